@@ -0,0 +1,130 @@
+package configly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/zanedma/configly/sources"
+)
+
+// wideConfig has many top-level fields of mixed types.
+type wideConfig struct {
+	Field01 int           `configly:"COUNT_1,min=0,max=1000000"`
+	Field02 bool          `configly:"FLAG_2,default=true"`
+	Field03 time.Duration `configly:"TIMEOUT_3,default=30s"`
+	Field04 string        `configly:"NAME_4,default=value"`
+	Field05 int           `configly:"COUNT_5,min=0,max=1000000"`
+	Field06 bool          `configly:"FLAG_6,default=true"`
+	Field07 time.Duration `configly:"TIMEOUT_7,default=30s"`
+	Field08 string        `configly:"NAME_8,default=value"`
+	Field09 int           `configly:"COUNT_9,min=0,max=1000000"`
+	Field10 bool          `configly:"FLAG_10,default=true"`
+	Field11 time.Duration `configly:"TIMEOUT_11,default=30s"`
+	Field12 string        `configly:"NAME_12,default=value"`
+	Field13 int           `configly:"COUNT_13,min=0,max=1000000"`
+	Field14 bool          `configly:"FLAG_14,default=true"`
+	Field15 time.Duration `configly:"TIMEOUT_15,default=30s"`
+	Field16 string        `configly:"NAME_16,default=value"`
+	Field17 int           `configly:"COUNT_17,min=0,max=1000000"`
+	Field18 bool          `configly:"FLAG_18,default=true"`
+	Field19 time.Duration `configly:"TIMEOUT_19,default=30s"`
+	Field20 string        `configly:"NAME_20,default=value"`
+	Field21 int           `configly:"COUNT_21,min=0,max=1000000"`
+	Field22 bool          `configly:"FLAG_22,default=true"`
+	Field23 time.Duration `configly:"TIMEOUT_23,default=30s"`
+	Field24 string        `configly:"NAME_24,default=value"`
+	Field25 int           `configly:"COUNT_25,min=0,max=1000000"`
+	Field26 bool          `configly:"FLAG_26,default=true"`
+	Field27 time.Duration `configly:"TIMEOUT_27,default=30s"`
+	Field28 string        `configly:"NAME_28,default=value"`
+	Field29 int           `configly:"COUNT_29,min=0,max=1000000"`
+	Field30 bool          `configly:"FLAG_30,default=true"`
+	Field31 time.Duration `configly:"TIMEOUT_31,default=30s"`
+	Field32 string        `configly:"NAME_32,default=value"`
+	Field33 int           `configly:"COUNT_33,min=0,max=1000000"`
+	Field34 bool          `configly:"FLAG_34,default=true"`
+	Field35 time.Duration `configly:"TIMEOUT_35,default=30s"`
+	Field36 string        `configly:"NAME_36,default=value"`
+	Field37 int           `configly:"COUNT_37,min=0,max=1000000"`
+	Field38 bool          `configly:"FLAG_38,default=true"`
+	Field39 time.Duration `configly:"TIMEOUT_39,default=30s"`
+	Field40 string        `configly:"NAME_40,default=value"`
+}
+
+// nestedBenchConfig has fields spread over nested structs.
+type nestedBenchConfig struct {
+	Server struct {
+		Host    string        `configly:"HOST,default=localhost,hostname"`
+		Port    int           `configly:"PORT,default=8080,port"`
+		Timeout time.Duration `configly:"TIMEOUT,default=30s"`
+		TLS     struct {
+			Enabled bool   `configly:"ENABLED,default=false"`
+			Cert    string `configly:"CERT,required_if=SERVER_TLS_ENABLED:true"`
+			Key     string `configly:"KEY,required_with=SERVER_TLS_CERT"`
+		} `configly:"TLS"`
+	} `configly:"SERVER"`
+	DB struct {
+		URL      string   `configly:"URL,required,url=postgres"`
+		MinConns int      `configly:"MIN_CONNS,default=2"`
+		MaxConns int      `configly:"MAX_CONNS,default=10,gtefield=MinConns"`
+		Replicas []string `configly:"REPLICAS,default='a.example.com,b.example.com',hostname"`
+	} `configly:"DB"`
+	Limits map[string]ByteSize `configly:"LIMITS,default='upload=64MiB,body=1MiB'"`
+}
+
+// quietLogs disables logging for the duration of a benchmark, so that
+// results measure loading rather than console output.
+func quietLogs(b *testing.B) {
+	level := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	b.Cleanup(func() { zerolog.SetGlobalLevel(level) })
+}
+
+func BenchmarkLoad(b *testing.B) {
+	quietLogs(b)
+	b.Run("wide", func(b *testing.B) {
+		source := sources.FromMap(map[string]string{"FLAG_2": "false", "NAME_4": "v", "FLAG_6": "false", "NAME_8": "v", "FLAG_10": "false", "NAME_12": "v", "FLAG_14": "false", "NAME_16": "v", "FLAG_18": "false", "NAME_20": "v", "FLAG_22": "false", "NAME_24": "v", "FLAG_26": "false", "NAME_28": "v", "FLAG_30": "false", "NAME_32": "v", "FLAG_34": "false", "NAME_36": "v", "FLAG_38": "false", "NAME_40": "v"})
+		l, err := New[wideConfig](LoaderConfig{Sources: []sources.Source{source}})
+		if err != nil {
+			b.Fatalf("expected no error, got: %s", err)
+		}
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := l.Load(); err != nil {
+				b.Fatalf("expected no error, got: %s", err)
+			}
+		}
+	})
+
+	b.Run("nested", func(b *testing.B) {
+		source := sources.FromMap(map[string]string{
+			"SERVER_HOST":        "api.example.com",
+			"SERVER_TLS_ENABLED": "true",
+			"SERVER_TLS_CERT":    "/etc/tls/cert.pem",
+			"SERVER_TLS_KEY":     "/etc/tls/key.pem",
+			"DB_URL":             "postgres://db.example.com/app",
+		})
+		l, err := New[nestedBenchConfig](LoaderConfig{Sources: []sources.Source{source}})
+		if err != nil {
+			b.Fatalf("expected no error, got: %s", err)
+		}
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := l.Load(); err != nil {
+				b.Fatalf("expected no error, got: %s", err)
+			}
+		}
+	})
+}
+
+func BenchmarkNew(b *testing.B) {
+	quietLogs(b)
+	source := sources.FromMap(nil)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := New[wideConfig](LoaderConfig{Sources: []sources.Source{source}}); err != nil {
+			b.Fatalf("expected no error, got: %s", err)
+		}
+	}
+}
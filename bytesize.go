@@ -0,0 +1,206 @@
+package configly
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ByteSize is a size in bytes that is loaded from values such as "64MiB",
+// "1.5GB", or "4096". Integer fields tagged bytes are parsed the same way.
+type ByteSize int64
+
+// Byte size units.
+const (
+	Byte ByteSize = 1
+
+	KB ByteSize = 1000
+	MB          = 1000 * KB
+	GB          = 1000 * MB
+	TB          = 1000 * GB
+	PB          = 1000 * TB
+	EB          = 1000 * PB
+
+	KiB ByteSize = 1 << 10
+	MiB          = 1 << 20
+	GiB          = 1 << 30
+	TiB          = 1 << 40
+	PiB          = 1 << 50
+	EiB          = 1 << 60
+)
+
+// byteUnits maps lowercase unit suffixes to their size. K, M, G, ... are SI
+// (powers of 1000) like KB, MB, GB.
+var byteUnits = map[string]ByteSize{
+	"":  Byte,
+	"b": Byte,
+	"k": KB, "kb": KB, "kib": KiB,
+	"m": MB, "mb": MB, "mib": MiB,
+	"g": GB, "gb": GB, "gib": GiB,
+	"t": TB, "tb": TB, "tib": TiB,
+	"p": PB, "pb": PB, "pib": PiB,
+	"e": EB, "eb": EB, "eib": EiB,
+}
+
+// formatUnits are the units String uses, largest first.
+var formatUnits = []struct {
+	name string
+	size ByteSize
+}{
+	{"EiB", EiB}, {"EB", EB}, {"PiB", PiB}, {"PB", PB}, {"TiB", TiB}, {"TB", TB},
+	{"GiB", GiB}, {"GB", GB}, {"MiB", MiB}, {"MB", MB}, {"KiB", KiB}, {"KB", KB},
+}
+
+// ParseByteSize parses a size such as "64MiB", "1.5GB", "512 KB", or "4096".
+// Units are case-insensitive: KB, MB, GB, TB, PB, and EB are powers of 1000,
+// and KiB, MiB, GiB, TiB, PiB, and EiB are powers of 1024. A number without a
+// unit is a count of bytes and may be written as an integer literal (see
+// parseInteger). Fractions must amount to a whole number of bytes.
+// Returns an error if s is malformed, negative, or larger than math.MaxInt64.
+func ParseByteSize(s string) (ByteSize, error) {
+	str := strings.TrimSpace(s)
+	if strings.HasPrefix(str, "-") {
+		return 0, fmt.Errorf("invalid byte size %q: must not be negative", s)
+	}
+	end := strings.IndexFunc(str, func(r rune) bool {
+		return !(r >= '0' && r <= '9' || r == '.' || r == '_' || r == '+')
+	})
+	if end == -1 {
+		end = len(str)
+	}
+	number, unit := str[:end], strings.ToLower(strings.TrimSpace(str[end:]))
+	if unit == "" || isPrefixedLiteral(str) {
+		n, err := parseInteger(str, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid byte size %q", s)
+		}
+		return ByteSize(n), nil
+	}
+
+	multiplier, ok := byteUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size %q: unknown unit %q", s, str[end:])
+	}
+	digits, err := stripDigitSeparators(number)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	value, ok := new(big.Rat).SetString(strings.TrimPrefix(digits, "+"))
+	if !ok || digits == "" {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	value.Mul(value, new(big.Rat).SetInt64(int64(multiplier)))
+	if !value.IsInt() {
+		return 0, fmt.Errorf("invalid byte size %q: not a whole number of bytes", s)
+	}
+	if !value.Num().IsInt64() {
+		return 0, fmt.Errorf("invalid byte size %q: exceeds maximum %d", s, int64(math.MaxInt64))
+	}
+	return ByteSize(value.Num().Int64()), nil
+}
+
+// String formats b with the largest unit that divides it exactly, e.g.
+// "64MiB" or "1500MB", or as a plain number of bytes. The result can be
+// parsed by ParseByteSize.
+func (b ByteSize) String() string {
+	if b != 0 {
+		for _, unit := range formatUnits {
+			if b%unit.size == 0 {
+				return strconv.FormatInt(int64(b/unit.size), 10) + unit.name
+			}
+		}
+	}
+	return strconv.FormatInt(int64(b), 10)
+}
+
+// parseInteger parses a signed integer literal that fits in bitSize bits.
+// Besides decimal it accepts underscore digit separators (1_000_000) and hex,
+// octal, and binary literals with a 0x, 0o, or 0b prefix. Unlike Go, a
+// leading zero alone does not make a literal octal: 0755 is decimal 755.
+// Out-of-range values return an error wrapping strconv.ErrRange.
+func parseInteger(s string, bitSize int) (int64, error) {
+	if isPrefixedLiteral(s) {
+		return strconv.ParseInt(s, 0, bitSize)
+	}
+	digits, err := stripDigitSeparators(s)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(digits, 10, bitSize)
+}
+
+// parseUnsigned parses an unsigned integer literal like parseInteger.
+func parseUnsigned(s string, bitSize int) (uint64, error) {
+	if isPrefixedLiteral(s) {
+		return strconv.ParseUint(s, 0, bitSize)
+	}
+	digits, err := stripDigitSeparators(s)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(digits, 10, bitSize)
+}
+
+// isPrefixedLiteral reports whether s, after an optional sign, starts with a
+// 0x, 0o, or 0b base prefix.
+func isPrefixedLiteral(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	if len(s) < 2 || s[0] != '0' {
+		return false
+	}
+	switch s[1] {
+	case 'x', 'X', 'o', 'O', 'b', 'B':
+		return true
+	}
+	return false
+}
+
+// stripDigitSeparators removes underscores that separate the digits of a
+// decimal literal. Returns an error if an underscore does not sit between
+// two digits.
+func stripDigitSeparators(s string) (string, error) {
+	if !strings.Contains(s, "_") {
+		return s, nil
+	}
+	digits := strings.TrimLeft(s, "+-")
+	sign := s[:len(s)-len(digits)]
+	for i, c := range digits {
+		if c == '_' && (i == 0 || i == len(digits)-1 || !isDigit(digits[i-1]) || !isDigit(digits[i+1])) {
+			return "", fmt.Errorf("invalid digit separator in %q", s)
+		}
+	}
+	return sign + strings.ReplaceAll(digits, "_", ""), nil
+}
+
+// checkByteSizeBounds checks size against the min and max of opts.
+func checkByteSizeBounds(size ByteSize, opts tagOptions) error {
+	if opts.min != nil && size < ByteSize(*opts.min) {
+		return fmt.Errorf("byte size %s is less than minimum %s", size, ByteSize(*opts.min))
+	}
+	if opts.max != nil && size > ByteSize(*opts.max) {
+		return fmt.Errorf("byte size %s exceeds maximum %s", size, ByteSize(*opts.max))
+	}
+	return nil
+}
+
+// isDigit reports whether c is a decimal digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// checkBytesOption reports a bytes option on a field of typ that is not an
+// integer, and min or max bounds written with units on a field that is not a
+// byte size.
+func checkBytesOption(opts tagOptions, typ reflect.Type) []error {
+	var optionErrors []error
+	if opts.bytes && (numericKind(typ.Kind()) != "int" && numericKind(typ.Kind()) != "uint" || typ == durationType) {
+		optionErrors = append(optionErrors, fmt.Errorf("invalid tag for %s: bytes is not supported for type %s", opts.key, typ))
+	}
+	if opts.sizeBounds && !opts.bytes && typ != byteSizeType {
+		optionErrors = append(optionErrors, fmt.Errorf("invalid tag for %s: min and max units are only supported for byte sizes", opts.key))
+	}
+	return optionErrors
+}
@@ -0,0 +1,154 @@
+package configly
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zanedma/configly/sources"
+)
+
+func TestParseByteSize(t *testing.T) {
+	valid := map[string]ByteSize{
+		"0":       0,
+		"4096":    4096,
+		"1_024":   1024,
+		"0x1000":  4096,
+		"64MiB":   64 * MiB,
+		"64mib":   64 * MiB,
+		"1.5GB":   1500 * MB,
+		"1.5 GiB": 1536 * MiB,
+		"512 KB":  512 * KB,
+		"10k":     10 * KB,
+		"100B":    100,
+		".5KiB":   512,
+	}
+	for str, expected := range valid {
+		size, err := ParseByteSize(str)
+		if err != nil {
+			t.Errorf("%q: expected no error, got: %s", str, err)
+			continue
+		}
+		if size != expected {
+			t.Errorf("%q: expected %d, got: %d", str, expected, size)
+		}
+	}
+
+	invalid := map[string]string{
+		"":       "invalid byte size",
+		"MiB":    "invalid byte size",
+		"-1KB":   "must not be negative",
+		"10XB":   `unknown unit "XB"`,
+		"1.5B":   "not a whole number of bytes",
+		"8EiB":   "exceeds maximum",
+		"1__0KB": "invalid byte size",
+		"_1KB":   "invalid byte size",
+		"1.5":    "invalid byte size",
+	}
+	for str, expected := range invalid {
+		_, err := ParseByteSize(str)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%q: expected error containing %q, got: %v", str, expected, err)
+		}
+	}
+}
+
+func TestByteSizeString(t *testing.T) {
+	tests := map[ByteSize]string{
+		0:          "0",
+		100:        "100",
+		64 * MiB:   "64MiB",
+		1500 * MB:  "1500MB",
+		2 * GiB:    "2GiB",
+		1000 * KiB: "1000KiB",
+	}
+	for size, expected := range tests {
+		if size.String() != expected {
+			t.Errorf("expected %s, got: %s", expected, size)
+		}
+		parsed, err := ParseByteSize(size.String())
+		if err != nil || parsed != size {
+			t.Errorf("expected %s to round-trip, got: %d, %v", size, parsed, err)
+		}
+	}
+}
+
+func TestParseInteger(t *testing.T) {
+	valid := map[string]int64{
+		"42":        42,
+		"-42":       -42,
+		"1_000_000": 1000000,
+		"0x1F":      31,
+		"0o755":     493,
+		"0b1010":    10,
+		"-0x10":     -16,
+		"0755":      755, // a leading zero stays decimal
+		"0x_FF":     255,
+	}
+	for str, expected := range valid {
+		val, err := parseInteger(str, 64)
+		if err != nil {
+			t.Errorf("%q: expected no error, got: %s", str, err)
+			continue
+		}
+		if val != expected {
+			t.Errorf("%q: expected %d, got: %d", str, expected, val)
+		}
+	}
+
+	for _, str := range []string{"1__0", "_1", "1_", "0xZ", "1e3", "1_.0"} {
+		if _, err := parseInteger(str, 64); err == nil {
+			t.Errorf("%q: expected error", str)
+		}
+	}
+}
+
+func TestLoadByteSizes(t *testing.T) {
+	type bufferConfig struct {
+		Upload ByteSize `configly:"UPLOAD,min=1KiB,max=64MiB"`
+		Buffer int      `configly:"BUFFER,bytes,default=4KiB"`
+		Chunk  uint32   `configly:"CHUNK,bytes,max=1MB"`
+		Mask   int      `configly:"MASK"`
+	}
+
+	t.Run("valid values", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{"UPLOAD": "1.5MiB", "CHUNK": "512KB", "MASK": "0o755"})
+		l, _ := New[bufferConfig](LoaderConfig{Sources: []sources.Source{source}})
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if cfg.Upload != 1536*KiB || cfg.Buffer != 4096 || cfg.Chunk != 512000 || cfg.Mask != 0o755 {
+			t.Errorf("unexpected values: %+v", cfg)
+		}
+	})
+
+	t.Run("bounds with units", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{"UPLOAD": "128MiB", "CHUNK": "2MB"})
+		l, _ := New[bufferConfig](LoaderConfig{Sources: []sources.Source{source}})
+		_, err := l.Load()
+		if err == nil {
+			t.Fatal("expected validation errors")
+		}
+		for _, expected := range []string{"byte size 128MiB exceeds maximum 64MiB", "byte size 2MB exceeds maximum 1MB"} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %s", expected, err)
+			}
+		}
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		type invalidConfig struct {
+			Name    string `configly:"NAME,bytes"`
+			Workers int    `configly:"WORKERS,max=1KiB"`
+		}
+		_, err := New[invalidConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}})
+		if err == nil {
+			t.Fatal("expected parse errors")
+		}
+		for _, expected := range []string{"bytes is not supported for type string", "min and max units are only supported for byte sizes"} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %s", expected, err)
+			}
+		}
+	})
+}
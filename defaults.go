@@ -0,0 +1,109 @@
+package configly
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/zanedma/configly/sources"
+)
+
+// defaultsSource converts the non-zero tagged fields of defaults into a
+// source named "defaults", keyed by each field's configuration key.
+// Returns an error if a field type cannot be converted.
+func (l *Loader[T]) defaultsSource(defaults *T) (sources.Source, error) {
+	val := reflect.ValueOf(defaults).Elem()
+	values := make(map[string]string, len(l.plan.fields))
+	var formatErrors []error
+	for _, opts := range l.plan.fields {
+		field := val.FieldByIndex(opts.fieldIndex)
+		if field.IsZero() {
+			continue
+		}
+		str, err := formatField(field, opts)
+		if err != nil {
+			formatErrors = append(formatErrors, fmt.Errorf("error converting default %s: %w", opts.key, err))
+			continue
+		}
+		values[opts.key] = str
+	}
+	if len(formatErrors) > 0 {
+		return nil, errors.Join(formatErrors...)
+	}
+	return sources.FromMapWithName(defaultsSourceName, values), nil
+}
+
+// formatField converts a struct field value into the string form setField
+// parses, the inverse of setField.
+// Returns an error for unsupported field types.
+func formatField(value reflect.Value, opts tagOptions) (string, error) {
+	switch value.Type() {
+	case timeType:
+		return value.Interface().(time.Time).Format(timeLayout(opts)), nil
+	case locationType:
+		return value.Interface().(*time.Location).String(), nil
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if value.Type() == durationType {
+			return time.Duration(value.Int()).String(), nil
+		}
+		if value.Type() == byteSizeType {
+			return ByteSize(value.Int()).String(), nil
+		}
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'g', -1, value.Type().Bits()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Slice:
+		items := make([]string, value.Len())
+		for i := range items {
+			item, err := formatListItem(value.Index(i), opts, ",")
+			if err != nil {
+				return "", fmt.Errorf("element %d: %w", i, err)
+			}
+			items[i] = item
+		}
+		return strings.Join(items, ","), nil
+	case reflect.Map:
+		var items []string
+		for _, key := range sortedMapKeys(value) {
+			formattedKey, err := formatListItem(key, tagOptions{}, ",=")
+			if err != nil {
+				return "", fmt.Errorf("map key %v: %w", key.Interface(), err)
+			}
+			formattedValue, err := formatListItem(value.MapIndex(key), opts, ",")
+			if err != nil {
+				return "", fmt.Errorf("map key %v: %w", key.Interface(), err)
+			}
+			items = append(items, formattedKey+"="+formattedValue)
+		}
+		return strings.Join(items, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported field type: %s", value.Kind())
+	}
+}
+
+// formatListItem formats a slice element, map key, or map value with
+// formatField. Returns an error if the formatted item contains one of
+// separators or surrounding whitespace, which setSlice and setMap would not
+// read back.
+func formatListItem(value reflect.Value, opts tagOptions, separators string) (string, error) {
+	str, err := formatField(value, opts)
+	if err != nil {
+		return "", err
+	}
+	if strings.ContainsAny(str, separators) || strings.TrimSpace(str) != str {
+		return "", fmt.Errorf("value %q cannot be written as a list item", str)
+	}
+	return str, nil
+}
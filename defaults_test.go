@@ -0,0 +1,100 @@
+package configly
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zanedma/configly/sources"
+)
+
+type defaultsConfig struct {
+	Host    string        `configly:"HOST,default=localhost"`
+	Port    int           `configly:"PORT,default=8080"`
+	Workers uint          `configly:"WORKERS,required"`
+	Timeout time.Duration `configly:"TIMEOUT"`
+	Debug   bool          `configly:"DEBUG"`
+}
+
+func TestLoaderDefaults(t *testing.T) {
+	t.Run("use defaults below sources and above tag defaults", func(t *testing.T) {
+		source := &sources.MockSource{SourceName: "env", Values: map[string]string{"PORT": "9090"}}
+		l, err := New[defaultsConfig](LoaderConfig{
+			Sources:  []sources.Source{source},
+			Defaults: &defaultsConfig{Host: "app.internal", Port: 7070, Workers: 4, Timeout: 3 * time.Second},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+
+		cfg, provenance, err := l.LoadWithProvenance()
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		expected := defaultsConfig{Host: "app.internal", Port: 9090, Workers: 4, Timeout: 3 * time.Second}
+		if *cfg != expected {
+			t.Errorf("expected %+v, got: %+v", expected, *cfg)
+		}
+		if provenance["HOST"].Source != defaultsSourceName {
+			t.Errorf("expected HOST to come from defaults, got: %+v", provenance["HOST"])
+		}
+		if provenance["PORT"].Source != "env" {
+			t.Errorf("expected PORT to come from env, got: %+v", provenance["PORT"])
+		}
+		if _, found := provenance["DEBUG"]; found {
+			t.Error("expected zero-valued DEBUG not to be supplied by defaults")
+		}
+	})
+
+	t.Run("do not modify the Sources slice", func(t *testing.T) {
+		srcs := make([]sources.Source, 1, 4)
+		srcs[0] = &sources.MockSource{SourceName: "env"}
+		if _, err := New[defaultsConfig](LoaderConfig{Sources: srcs, Defaults: &defaultsConfig{Workers: 1}}); err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if extended := srcs[:2]; extended[1] != nil {
+			t.Error("expected caller's Sources backing array to be untouched")
+		}
+	})
+
+	t.Run("error for wrong defaults type", func(t *testing.T) {
+		source := &sources.MockSource{SourceName: "env"}
+		if _, err := New[defaultsConfig](LoaderConfig{Sources: []sources.Source{source}, Defaults: defaultsConfig{}}); err == nil {
+			t.Error("expected error for non-pointer defaults")
+		}
+		if _, err := New[defaultsConfig](LoaderConfig{Sources: []sources.Source{source}, Defaults: (*defaultsConfig)(nil)}); err == nil {
+			t.Error("expected error for nil defaults")
+		}
+	})
+}
+
+func TestFormatField(t *testing.T) {
+	type allTypes struct {
+		S   string
+		I   int8
+		U   uint16
+		F32 float32
+		F64 float64
+		B   bool
+		D   time.Duration
+	}
+	in := allTypes{S: "text", I: -5, U: 65535, F32: 0.1, F64: 2.5e-9, B: true, D: 90 * time.Second}
+
+	l, _ := New[allTypes](LoaderConfig{Sources: []sources.Source{&sources.MockSource{}}})
+	var out allTypes
+	inVal := reflect.ValueOf(in)
+	outVal := reflect.ValueOf(&out).Elem()
+	for i := range inVal.NumField() {
+		str, err := formatField(inVal.Field(i), tagOptions{})
+		if err != nil {
+			t.Fatalf("expected no error formatting %s, got: %s", inVal.Type().Field(i).Name, err)
+		}
+		field := outVal.Field(i)
+		if err := l.setField(&field, str, tagOptions{}); err != nil {
+			t.Fatalf("expected %q to parse back into %s, got: %s", str, inVal.Type().Field(i).Name, err)
+		}
+	}
+	if out != in {
+		t.Errorf("expected round trip to preserve %+v, got: %+v", in, out)
+	}
+}
@@ -0,0 +1,196 @@
+package configly
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Change describes a configuration key whose value differs between two
+// instances of a configuration type (see Diff).
+type Change struct {
+	Key       string // Configuration key, e.g. TLS_CERT
+	Field     string // Dotted Go path of the field, e.g. TLS.Cert
+	Old       string // Value in the old instance, in its string form, or a mask for secrets
+	New       string // Value in the new instance, in its string form, or a mask for secrets
+	OldSource string // Source of the old value, if known (see DiffWithProvenance)
+	NewSource string // Source of the new value, if known (see DiffWithProvenance)
+	Secret    bool   // Whether the field is tagged secret or either value came from a secret
+	Restart   bool   // Whether applying the change requires a restart, i.e. the field is not tagged reloadable
+}
+
+// String formats the change as "KEY: old -> new", naming the sources of the
+// values when they are known.
+func (c Change) String() string {
+	return fmt.Sprintf("%s: %s -> %s", c.Key, withSource(c.Old, c.OldSource), withSource(c.New, c.NewSource))
+}
+
+// withSource appends the source of value in parentheses, if known.
+func withSource(value, source string) string {
+	if value == "" {
+		value = `""`
+	}
+	if source == "" {
+		return value
+	}
+	return fmt.Sprintf("%s (%s)", value, source)
+}
+
+// ChangeSet is the list of changes between two configurations, in field
+// declaration order.
+type ChangeSet []Change
+
+// RestartRequired reports whether any change is to a field that is not tagged
+// reloadable.
+func (cs ChangeSet) RestartRequired() bool {
+	return slices.ContainsFunc(cs, func(c Change) bool { return c.Restart })
+}
+
+// Keys returns the configuration keys of the changes.
+func (cs ChangeSet) Keys() []string {
+	keys := make([]string, len(cs))
+	for i, c := range cs {
+		keys[i] = c.Key
+	}
+	return keys
+}
+
+// Diff compares two configurations of type T field by field, using the
+// configly struct tag, and returns a change for every tagged field whose
+// value differs. Fields of nested structs are compared individually, and
+// slices and maps are compared as a whole. Values are reported in the string
+// form Load parses, with fields tagged secret masked. Changes to fields that
+// are not tagged reloadable are marked as requiring a restart.
+// To report where values came from, or for types using another tag key, use
+// Loader.DiffWithProvenance.
+// Returns an error if a or b is nil or a tag cannot be split into options.
+func Diff[T any](a, b *T) (ChangeSet, error) {
+	if a == nil || b == nil {
+		return nil, errors.New("cannot diff nil configurations")
+	}
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("invalid type for %s: %s (must be struct)", typ.Name(), typ.Kind())
+	}
+	fields, err := diffFields(typ, defaultTagKey, nil, "", "")
+	if err != nil {
+		return nil, err
+	}
+	return diffValues(fields, reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem(), nil, nil), nil
+}
+
+// DiffWithProvenance compares two configurations like Diff, using the
+// Loader's tag key, and additionally reports the source of each value from
+// the provenance returned by LoadWithProvenance. Values from secret sources
+// are masked like fields tagged secret. Either provenance may be nil.
+// Returns an error if a or b is nil.
+func (l *Loader[T]) DiffWithProvenance(a *T, aProvenance Provenance, b *T, bProvenance Provenance) (ChangeSet, error) {
+	if a == nil || b == nil {
+		return nil, errors.New("cannot diff nil configurations")
+	}
+	return diffValues(l.plan.fields, reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem(), aProvenance, bProvenance), nil
+}
+
+// diffFields lists the tagged fields of typ for Diff. Unlike parseStructTags,
+// it reads only each tag's key and the secret and reloadable options, so
+// types whose tags use custom validators can be compared without a Loader.
+// Returns an error for tags that cannot be split into options.
+func diffFields(typ reflect.Type, tagKey string, index []int, keyPrefix, pathPrefix string) ([]tagOptions, error) {
+	var fields []tagOptions
+	var splitErrors []error
+	for idx := range typ.NumField() {
+		field := typ.Field(idx)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldIndex := append(slices.Clip(index), idx)
+		tag := field.Tag.Get(tagKey)
+		if isNestedStruct(field.Type) {
+			prefix, _, _ := strings.Cut(tag, ",")
+			if prefix != "" {
+				prefix += nestedKeySeparator
+			}
+			nested, err := diffFields(field.Type, tagKey, fieldIndex, keyPrefix+prefix, pathPrefix+field.Name+".")
+			fields = append(fields, nested...)
+			if err != nil {
+				splitErrors = append(splitErrors, err)
+			}
+			continue
+		}
+		if tag == "" {
+			continue
+		}
+
+		parts, err := splitTag(tag)
+		if err != nil {
+			splitErrors = append(splitErrors, err)
+			continue
+		}
+		fields = append(fields, tagOptions{
+			key:        keyPrefix + parts[0],
+			fieldIndex: fieldIndex,
+			fieldPath:  pathPrefix + field.Name,
+			secret:     slices.Contains(parts[1:], "secret"),
+			reloadable: slices.Contains(parts[1:], "reloadable"),
+		})
+	}
+	return fields, errors.Join(splitErrors...)
+}
+
+// diffValues compares the fields of a and b, taking the sources of values
+// from the provenances, which may be nil.
+func diffValues(fields []tagOptions, a, b reflect.Value, aProvenance, bProvenance Provenance) ChangeSet {
+	var changes ChangeSet
+	for _, opts := range fields {
+		oldValue, newValue := a.FieldByIndex(opts.fieldIndex), b.FieldByIndex(opts.fieldIndex)
+		if valuesEqual(oldValue, newValue) {
+			continue
+		}
+
+		oldOrigin, newOrigin := aProvenance[opts.key], bProvenance[opts.key]
+		change := Change{
+			Key:       opts.key,
+			Field:     opts.fieldPath,
+			Old:       formatDiffValue(oldValue, opts),
+			New:       formatDiffValue(newValue, opts),
+			OldSource: oldOrigin.Source,
+			NewSource: newOrigin.Source,
+			Secret:    opts.secret || oldOrigin.Secret || newOrigin.Secret,
+			Restart:   !opts.reloadable,
+		}
+		if change.Secret {
+			change.Old, change.New = maskedValue, maskedValue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// valuesEqual reports whether two values of a field are the same. Times are
+// equal when they denote the same instant, and empty slices and maps are
+// equal whether or not they are nil.
+func valuesEqual(a, b reflect.Value) bool {
+	switch {
+	case a.Type() == timeType:
+		return a.Interface().(time.Time).Equal(b.Interface().(time.Time))
+	case a.Kind() == reflect.Slice || a.Kind() == reflect.Map:
+		if a.Len() == 0 && b.Len() == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
+
+// formatDiffValue formats a field value like LoaderConfig.Defaults values
+// (see formatField), falling back to its default format for values that have
+// no string form Load could parse.
+func formatDiffValue(value reflect.Value, opts tagOptions) string {
+	if str, err := formatField(value, opts); err == nil {
+		return str
+	}
+	return fmt.Sprint(value.Interface())
+}
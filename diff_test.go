@@ -0,0 +1,140 @@
+package configly
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zanedma/configly/sources"
+)
+
+type diffConfig struct {
+	Port     int            `configly:"PORT,port"`
+	LogLevel string         `configly:"LOG_LEVEL,reloadable"`
+	Password string         `configly:"PASSWORD,secret"`
+	Region   string         `configly:"REGION,awsRegion"`
+	Started  time.Time      `configly:"STARTED,reloadable"`
+	Hosts    []string       `configly:"HOSTS,reloadable"`
+	Limits   map[string]int `configly:"LIMITS,reloadable"`
+	TLS      struct {
+		Cert string `configly:"CERT,restart"`
+	} `configly:"TLS"`
+}
+
+func TestDiff(t *testing.T) {
+	started := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	a := &diffConfig{Port: 8080, LogLevel: "info", Password: "old-secret", Region: "eu-west-1", Started: started, Limits: map[string]int{}}
+	b := *a
+	b.Started = started.In(time.FixedZone("CET", 3600))
+	b.Hosts = nil
+
+	t.Run("no changes", func(t *testing.T) {
+		changes, err := Diff(a, &b)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if len(changes) != 0 {
+			t.Errorf("expected no changes, got: %v", changes)
+		}
+	})
+
+	t.Run("reloadable changes", func(t *testing.T) {
+		b := *a
+		b.LogLevel = "debug"
+		b.Hosts = []string{"a.example.com", "b.example.com"}
+		b.Limits = map[string]int{"eu": 10}
+		changes, err := Diff(a, &b)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if !slices.Equal(changes.Keys(), []string{"LOG_LEVEL", "HOSTS", "LIMITS"}) {
+			t.Fatalf("unexpected changes: %v", changes)
+		}
+		if changes[1].Old != "" || changes[1].New != "a.example.com,b.example.com" || changes[2].New != "eu=10" {
+			t.Errorf("unexpected formatted values: %v", changes)
+		}
+		if changes.RestartRequired() {
+			t.Errorf("expected reloadable changes not to require a restart: %v", changes)
+		}
+	})
+
+	t.Run("restart and secret changes", func(t *testing.T) {
+		b := *a
+		b.Port = 9090
+		b.Password = "new-secret"
+		b.TLS.Cert = "/etc/tls/cert.pem"
+		changes, err := Diff(a, &b)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if !slices.Equal(changes.Keys(), []string{"PORT", "PASSWORD", "TLS_CERT"}) {
+			t.Fatalf("unexpected changes: %v", changes)
+		}
+		if !changes.RestartRequired() || !changes[0].Restart {
+			t.Errorf("expected port change to require a restart: %v", changes)
+		}
+		if changes[1].Old != maskedValue || changes[1].New != maskedValue || !changes[1].Secret {
+			t.Errorf("expected masked password, got: %+v", changes[1])
+		}
+		if changes[2].Field != "TLS.Cert" || changes[2].String() != `TLS_CERT: "" -> /etc/tls/cert.pem` {
+			t.Errorf("unexpected nested change: %+v (%s)", changes[2], changes[2])
+		}
+		for _, c := range changes {
+			if strings.Contains(c.String(), "secret") {
+				t.Errorf("expected secret to be masked, got: %s", c)
+			}
+		}
+	})
+
+	if _, err := Diff(a, nil); err == nil {
+		t.Error("expected error for nil configuration")
+	}
+}
+
+func TestDiffWithProvenance(t *testing.T) {
+	l, err := New[diffConfig](LoaderConfig{
+		Sources:    []sources.Source{sources.FromMap(map[string]string{"PORT": "8080", "PASSWORD": "hunter2"})},
+		Validators: map[string]ValidatorFunc{"awsRegion": func(any, string) error { return nil }},
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %s", err)
+	}
+	a, aProvenance, err := l.LoadWithProvenance()
+	if err != nil {
+		t.Fatalf("expected no error, got: %s", err)
+	}
+	if !aProvenance["PASSWORD"].Secret {
+		t.Errorf("expected secret tag to be recorded in provenance, got: %+v", aProvenance["PASSWORD"])
+	}
+
+	l.sources = []sources.Source{sources.FromMapWithName("env", map[string]string{"PORT": "9090", "LOG_LEVEL": "warn"})}
+	b, bProvenance, err := l.LoadWithProvenance()
+	if err != nil {
+		t.Fatalf("expected no error, got: %s", err)
+	}
+
+	changes, err := l.DiffWithProvenance(a, aProvenance, b, bProvenance)
+	if err != nil {
+		t.Fatalf("expected no error, got: %s", err)
+	}
+	expected := []string{"PORT: 8080 (map) -> 9090 (env)", `LOG_LEVEL: "" -> warn (env)`, "PASSWORD: **** (map) -> ****"}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, got: %v", len(expected), changes)
+	}
+	for i, c := range changes {
+		if c.String() != expected[i] {
+			t.Errorf("expected %q, got: %q", expected[i], c)
+		}
+	}
+}
+
+func TestReloadTagOptions(t *testing.T) {
+	type invalidConfig struct {
+		Port int `configly:"PORT,reloadable,restart"`
+	}
+	_, err := New[invalidConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}})
+	if err == nil || !strings.Contains(err.Error(), "reloadable and restart are mutually exclusive") {
+		t.Errorf("expected mutually exclusive error, got: %v", err)
+	}
+}
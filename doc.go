@@ -34,15 +34,43 @@
 //	    log.Fatal(err)
 //	}
 //
+// LoadInto loads into an existing instance, keeping fields that have no value
+// in any source, and LoadFields refreshes selected fields only.
+//
 // # Validation Tags
 //
 // Available struct tag options:
 //   - required: Field must have a value
-//   - default=VALUE: Default value if not found
+//   - default=VALUE: Default value if not found (default= for an explicit
+//     empty default); with LoaderConfig.Interpolate, defaults may reference
+//     ${KEY} and ${env:VAR}
 //   - min=N: Minimum value for numbers
 //   - max=N: Maximum value for numbers
 //   - minLen=N: Minimum string length
 //   - maxLen=N: Maximum string length
+//   - pattern=REGEXP: String must match the regular expression
+//   - oneof=VALUE...: Must be one of the space-separated values (strings and
+//     integers)
+//   - noexpand: Never expand ${KEY} references (see LoaderConfig.Interpolate)
+//   - required_if=KEY:VALUE: Required when the field loaded from KEY equals VALUE
+//   - required_with=KEY...: Required when any of the listed keys has a value
+//   - excluded_with=KEY...: Must not be set when any of the listed keys has a value
+//   - gtfield, gtefield, ltfield, ltefield=Field: Compare with a sibling field
+//   - url[=SCHEMES], hostname, ip, ipv4, ipv6, cidr, email, port, file, dir,
+//     absPath: Semantic checks on string fields (port also on integers)
+//   - layout=LAYOUT: Time layout for time.Time fields
+//   - bytes: Parse an integer field as a byte size (min and max may use units)
+//   - secret: Mask the value in logs, errors, provenance, and diffs
+//   - reloadable, restart: Whether a change needs a restart (see Diff)
+//   - custom options registered in LoaderConfig.Validators
+//
+// Option values containing commas are single-quoted, e.g. default='a,b'.
+// Tags are parsed once per type by New, so unknown options and invalid
+// defaults are reported as errors by New rather than by Load.
+//
+// Nested struct fields are loaded recursively, with the struct field's tag as
+// a key prefix (e.g. TLS_CERT). Types and nested structs implementing
+// Validate() error are validated after loading.
 //
 // # Multiple Sources
 //
@@ -60,11 +88,21 @@
 //
 // Configly supports the following field types:
 //   - string
-//   - bool
+//   - bool (also yes/no, on/off, enabled/disabled)
 //   - int, int8, int16, int32, int64
 //   - uint, uint8, uint16, uint32, uint64
 //   - float32, float64
-//   - time.Duration
+//   - time.Duration (also d and w units, e.g. 7d)
+//   - time.Time, parsed with the layout=LAYOUT option (RFC3339 by default)
+//   - *time.Location, from IANA time zone names
+//   - ByteSize, written with units such as 64MiB or 1.5GB (also integer
+//     fields tagged bytes)
+//   - slices and maps of the types above, written as "a,b" and "k=v,k2=v2"
+//   - nested structs
+//
+// Integers may be written with digit separators (1_000) and 0x, 0o, or 0b
+// prefixes. Values that do not fit the field's type are reported with the
+// type's range instead of wrapping.
 //
 // See the sources subpackage for available configuration sources including
 // FromFile() for JSON, YAML, and .env files.
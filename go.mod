@@ -2,12 +2,14 @@ module github.com/zanedma/configly
 
 go 1.24.1
 
-require gopkg.in/yaml.v3 v3.0.1
+require (
+	github.com/joho/godotenv v1.5.1
+	github.com/rs/zerolog v1.34.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
-	github.com/joho/godotenv v1.5.1 // indirect
 	github.com/mattn/go-colorable v0.1.14 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	github.com/rs/zerolog v1.34.0 // indirect
 	golang.org/x/sys v0.38.0 // indirect
 )
@@ -0,0 +1,98 @@
+package configly
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/zanedma/configly/sources"
+)
+
+const (
+	// fileKeySuffix is appended to a key to look up the path of a file holding
+	// its value, following the POSTGRES_PASSWORD_FILE convention.
+	fileKeySuffix = "_FILE"
+	// fileURLPrefix marks a value as a reference to a file (file:///path).
+	fileURLPrefix = "file://"
+	// fileAtPrefix marks a value as a reference to an absolute path (@/path).
+	fileAtPrefix = "@/"
+)
+
+// resolveValue retrieves the value for key from the configured sources and
+// records its origin. Without file indirection this is a plain priority
+// lookup. With file indirection, each source is asked for KEY and then for
+// KEY_FILE before moving on to the next source, so a KEY_FILE in a higher
+// priority source wins over a KEY in a lower priority one. Values referencing
+// a file (file:///path or @/path) are replaced with the file's content.
+// Returns an error if a referenced file cannot be read.
+func (l *Loader[T]) resolveValue(key string) (string, Origin, bool, error) {
+	return l.resolveValueFrom(l.sources, key)
+}
+
+// resolveValueFrom resolves key like resolveValue, using only srcs.
+func (l *Loader[T]) resolveValueFrom(srcs []sources.Source, key string) (string, Origin, bool, error) {
+	if !l.fileIndirection {
+		val, origin, found := l.getValueFromSources(srcs, key)
+		return val, origin, found, nil
+	}
+
+	for _, source := range srcs {
+		if val, found := l.getValueFromSource(source, key); found {
+			origin := sourceOrigin(source, key)
+			path, isRef := fileReference(val)
+			if !isRef {
+				return val, origin, true, nil
+			}
+			content, err := readIndirectFile(path, l.maxFileSize)
+			if err != nil {
+				return "", origin, false, fmt.Errorf("error resolving %s (source %s): %w", key, origin.Source, err)
+			}
+			origin.File = path
+			return content, origin, true, nil
+		}
+
+		fileKey := key + fileKeySuffix
+		if path, found := l.getValueFromSource(source, fileKey); found {
+			origin := Origin{Source: source.Name(), Key: fileKey, File: path}
+			content, err := readIndirectFile(path, l.maxFileSize)
+			if err != nil {
+				return "", origin, false, fmt.Errorf("error resolving %s (source %s): %w", fileKey, origin.Source, err)
+			}
+			return content, origin, true, nil
+		}
+	}
+	return "", Origin{}, false, nil
+}
+
+// fileReference reports whether val references a file and returns its path.
+// Supported forms are "file:///path" and "@/path".
+func fileReference(val string) (string, bool) {
+	if path, ok := strings.CutPrefix(val, fileURLPrefix); ok {
+		return path, true
+	}
+	if strings.HasPrefix(val, fileAtPrefix) {
+		return strings.TrimPrefix(val, "@"), true
+	}
+	return "", false
+}
+
+// readIndirectFile reads the content of a file referenced by a value,
+// trimming trailing newlines the way shells do for $(< file).
+// Returns an error if the file is larger than maxSize bytes.
+func readIndirectFile(path string, maxSize int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading file: %w", err)
+	}
+	defer f.Close()
+
+	bytes, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return "", fmt.Errorf("error reading file: %w", err)
+	}
+	if int64(len(bytes)) > maxSize {
+		return "", fmt.Errorf("file %s exceeds maximum size of %d bytes", path, maxSize)
+	}
+	return strings.TrimRight(string(bytes), "\r\n"), nil
+}
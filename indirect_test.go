@@ -0,0 +1,164 @@
+package configly
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zanedma/configly/sources"
+)
+
+type indirectConfig struct {
+	Password string `configly:"DB_PASSWORD,required"`
+}
+
+func TestFileIndirection(t *testing.T) {
+	t.Run("read value from KEY_FILE", func(t *testing.T) {
+		path := writeTestFile(t, "password", "s3cret\n")
+		source := &sources.MockSource{
+			SourceName: "env",
+			Values:     map[string]string{"DB_PASSWORD_FILE": path},
+		}
+		l, _ := New[indirectConfig](LoaderConfig{
+			Sources:         []sources.Source{source},
+			FileIndirection: true,
+		})
+
+		cfg, provenance, err := l.LoadWithProvenance()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.Password != "s3cret" {
+			t.Errorf("expected Password to be 's3cret', got: %q", cfg.Password)
+		}
+		origin := provenance["DB_PASSWORD"]
+		if origin.Source != "env" || origin.Key != "DB_PASSWORD_FILE" || origin.File != path {
+			t.Errorf("expected origin to record env, DB_PASSWORD_FILE and %s, got: %+v", path, origin)
+		}
+	})
+
+	t.Run("KEY wins over KEY_FILE in the same source", func(t *testing.T) {
+		path := writeTestFile(t, "password", "from-file")
+		source := &sources.MockSource{
+			SourceName: "env",
+			Values:     map[string]string{"DB_PASSWORD": "direct", "DB_PASSWORD_FILE": path},
+		}
+		l, _ := New[indirectConfig](LoaderConfig{
+			Sources:         []sources.Source{source},
+			FileIndirection: true,
+		})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.Password != "direct" {
+			t.Errorf("expected Password to be 'direct', got: %q", cfg.Password)
+		}
+	})
+
+	t.Run("KEY_FILE in higher priority source wins", func(t *testing.T) {
+		path := writeTestFile(t, "password", "from-file")
+		source1 := &sources.MockSource{
+			SourceName: "source1",
+			Values:     map[string]string{"DB_PASSWORD_FILE": path},
+		}
+		source2 := &sources.MockSource{
+			SourceName: "source2",
+			Values:     map[string]string{"DB_PASSWORD": "direct"},
+		}
+		l, _ := New[indirectConfig](LoaderConfig{
+			Sources:         []sources.Source{source1, source2},
+			FileIndirection: true,
+		})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.Password != "from-file" {
+			t.Errorf("expected Password to be 'from-file', got: %q", cfg.Password)
+		}
+	})
+
+	t.Run("resolve file:// and @ references", func(t *testing.T) {
+		path := writeTestFile(t, "password", "s3cret")
+		for _, ref := range []string{"file://" + path, "@" + path} {
+			source := &sources.MockSource{
+				SourceName: "test",
+				Values:     map[string]string{"DB_PASSWORD": ref},
+			}
+			l, _ := New[indirectConfig](LoaderConfig{
+				Sources:         []sources.Source{source},
+				FileIndirection: true,
+			})
+
+			cfg, provenance, err := l.LoadWithProvenance()
+			if err != nil {
+				t.Fatalf("expected err to be nil for %s, got: %s", ref, err)
+			}
+			if cfg.Password != "s3cret" {
+				t.Errorf("expected Password to be 's3cret' for %s, got: %q", ref, cfg.Password)
+			}
+			if provenance["DB_PASSWORD"].File != path {
+				t.Errorf("expected origin file to be %s, got: %s", path, provenance["DB_PASSWORD"].File)
+			}
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		path := writeTestFile(t, "password", "s3cret")
+		source := &sources.MockSource{
+			SourceName: "test",
+			Values:     map[string]string{"DB_PASSWORD": "file://" + path},
+		}
+		l, _ := New[indirectConfig](LoaderConfig{Sources: []sources.Source{source}})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.Password != "file://"+path {
+			t.Errorf("expected reference to be kept as-is, got: %q", cfg.Password)
+		}
+	})
+
+	t.Run("file exceeding maximum size", func(t *testing.T) {
+		path := writeTestFile(t, "password", strings.Repeat("x", 11))
+		source := &sources.MockSource{
+			SourceName: "test",
+			Values:     map[string]string{"DB_PASSWORD_FILE": path},
+		}
+		l, _ := New[indirectConfig](LoaderConfig{
+			Sources:         []sources.Source{source},
+			FileIndirection: true,
+			MaxFileSize:     10,
+		})
+
+		_, err := l.Load()
+		if err == nil {
+			t.Fatal("expected error for oversized file")
+		}
+		if !strings.Contains(err.Error(), "exceeds maximum size") {
+			t.Errorf("expected size error, got: %s", err)
+		}
+	})
+
+	t.Run("missing referenced file", func(t *testing.T) {
+		source := &sources.MockSource{
+			SourceName: "test",
+			Values:     map[string]string{"DB_PASSWORD_FILE": "/nonexistent/password"},
+		}
+		l, _ := New[indirectConfig](LoaderConfig{
+			Sources:         []sources.Source{source},
+			FileIndirection: true,
+		})
+
+		_, err := l.Load()
+		if err == nil {
+			t.Fatal("expected error for missing file")
+		}
+		if !strings.Contains(err.Error(), "DB_PASSWORD_FILE") {
+			t.Errorf("expected error to name DB_PASSWORD_FILE, got: %s", err)
+		}
+	})
+}
@@ -0,0 +1,125 @@
+package configly
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envReferencePrefix marks a ${env:VAR} reference to an environment variable.
+const envReferencePrefix = "env:"
+
+// expand replaces ${KEY} and ${KEY:-fallback} references in value with the
+// values of the referenced keys, ${env:VAR} references with the value of the
+// environment variable VAR, and $$ with a literal $. References are
+// resolved through the same source priority chain as field values; a key
+// that is not found in any source falls back to the inline fallback if one is
+// given, then to the referenced field's tag default. Fallbacks are used when
+// the referenced value is missing or empty, as in the shell.
+//
+// fields maps configuration keys to their tag options and stack holds the keys
+// currently being expanded, which is used to detect reference cycles.
+// Returns an error for unterminated references, unresolvable keys, and cycles.
+func (l *Loader[T]) expand(value string, fields map[string]tagOptions, stack []string) (string, error) {
+	if !strings.Contains(value, "$") {
+		return value, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+		switch value[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+		case '{':
+			end := matchingBrace(value, i+2)
+			if end < 0 {
+				return "", fmt.Errorf("unterminated reference in %q", value)
+			}
+			expanded, err := l.expandReference(value[i+2:end], fields, stack)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(expanded)
+			i = end
+		default:
+			sb.WriteByte('$')
+		}
+	}
+	return sb.String(), nil
+}
+
+// expandReference resolves the body of a single ${...} reference, which is
+// KEY, env:VAR, or either followed by :-fallback.
+func (l *Loader[T]) expandReference(ref string, fields map[string]tagOptions, stack []string) (string, error) {
+	key, fallback, hasFallback := strings.Cut(ref, ":-")
+	if key == "" {
+		return "", fmt.Errorf("empty reference ${%s}", ref)
+	}
+	if name, isEnv := strings.CutPrefix(key, envReferencePrefix); isEnv {
+		value, found := os.LookupEnv(name)
+		switch {
+		case name == "":
+			return "", fmt.Errorf("empty reference ${%s}", ref)
+		case value != "":
+			return value, nil
+		case hasFallback:
+			return l.expand(fallback, fields, stack)
+		case found:
+			return "", nil
+		}
+		return "", fmt.Errorf("unresolved reference ${%s}: environment variable %s is not set", key, name)
+	}
+	for _, resolving := range stack {
+		if resolving == key {
+			return "", fmt.Errorf("reference cycle: %s -> %s", strings.Join(stack, " -> "), key)
+		}
+	}
+
+	opts, isField := fields[key]
+	value, _, found, err := l.resolveValue(key)
+	if err != nil {
+		return "", err
+	}
+	if found && value != "" {
+		if isField && opts.noExpand {
+			return value, nil
+		}
+		return l.expand(value, fields, append(stack, key))
+	}
+
+	if hasFallback {
+		return l.expand(fallback, fields, stack)
+	}
+	if isField && opts.hasDefault {
+		return l.expand(opts.defaultValue, fields, append(stack, key))
+	}
+	if found {
+		return "", nil
+	}
+	return "", fmt.Errorf("unresolved reference ${%s}", key)
+}
+
+// matchingBrace returns the index of the '}' closing a reference whose body
+// starts at start, accounting for nested ${...} references in fallbacks.
+// Returns -1 if the reference is not terminated.
+func matchingBrace(value string, start int) int {
+	depth := 1
+	for i := start; i < len(value); i++ {
+		switch {
+		case value[i] == '$' && i+1 < len(value) && value[i+1] == '{':
+			depth++
+			i++
+		case value[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
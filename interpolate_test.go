@@ -0,0 +1,171 @@
+package configly
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zanedma/configly/sources"
+)
+
+type interpolateConfig struct {
+	Host     string `configly:"HOST,default=localhost"`
+	APIURL   string `configly:"API_URL,default=http://${HOST}:8080"`
+	DBURL    string `configly:"DB_URL"`
+	Password string `configly:"PASSWORD,noexpand"`
+}
+
+func TestInterpolation(t *testing.T) {
+	newLoader := func(values map[string]string) *Loader[interpolateConfig] {
+		l, _ := New[interpolateConfig](LoaderConfig{
+			Sources:     []sources.Source{&sources.MockSource{SourceName: "test", Values: values}},
+			Interpolate: true,
+		})
+		return l
+	}
+
+	t.Run("expand reference in value", func(t *testing.T) {
+		l := newLoader(map[string]string{"HOST": "db.internal", "DB_URL": "postgres://${HOST}/app"})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.DBURL != "postgres://db.internal/app" {
+			t.Errorf("expected DB_URL to be expanded, got: %s", cfg.DBURL)
+		}
+	})
+
+	t.Run("expand reference in default", func(t *testing.T) {
+		l := newLoader(map[string]string{"HOST": "api.internal"})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.APIURL != "http://api.internal:8080" {
+			t.Errorf("expected API_URL default to be expanded, got: %s", cfg.APIURL)
+		}
+	})
+
+	t.Run("referenced key falls back to its tag default", func(t *testing.T) {
+		l := newLoader(map[string]string{"DB_URL": "postgres://${HOST}/app"})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.DBURL != "postgres://localhost/app" {
+			t.Errorf("expected HOST default to be used, got: %s", cfg.DBURL)
+		}
+	})
+
+	t.Run("inline fallback", func(t *testing.T) {
+		l := newLoader(map[string]string{"DB_URL": "postgres://${DB_HOST:-${HOST}}:${DB_PORT:-5432}/app"})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.DBURL != "postgres://localhost:5432/app" {
+			t.Errorf("expected fallbacks to be used, got: %s", cfg.DBURL)
+		}
+	})
+
+	t.Run("escaped dollar", func(t *testing.T) {
+		l := newLoader(map[string]string{"DB_URL": "cost $$5 and ${HOST} and $PLAIN"})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.DBURL != "cost $5 and localhost and $PLAIN" {
+			t.Errorf("expected literal dollars, got: %s", cfg.DBURL)
+		}
+	})
+
+	t.Run("noexpand keeps value as-is", func(t *testing.T) {
+		l := newLoader(map[string]string{"PASSWORD": "pa$${HOST}"})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.Password != "pa$${HOST}" {
+			t.Errorf("expected Password to be unexpanded, got: %s", cfg.Password)
+		}
+	})
+
+	t.Run("referencing a noexpand key uses its raw value", func(t *testing.T) {
+		l := newLoader(map[string]string{"PASSWORD": "p${x}", "DB_URL": "postgres://u:${PASSWORD}@db"})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.DBURL != "postgres://u:p${x}@db" {
+			t.Errorf("expected raw PASSWORD in DB_URL, got: %s", cfg.DBURL)
+		}
+	})
+
+	t.Run("cycle is reported", func(t *testing.T) {
+		l := newLoader(map[string]string{"HOST": "${DB_URL}", "DB_URL": "${HOST}"})
+
+		_, err := l.Load()
+		if err == nil {
+			t.Fatal("expected error for reference cycle")
+		}
+		if !strings.Contains(err.Error(), "reference cycle: HOST -> DB_URL -> HOST") {
+			t.Errorf("expected cycle path in error, got: %s", err)
+		}
+	})
+
+	t.Run("unresolved reference", func(t *testing.T) {
+		l := newLoader(map[string]string{"DB_URL": "${MISSING}"})
+
+		_, err := l.Load()
+		if err == nil {
+			t.Fatal("expected error for unresolved reference")
+		}
+		if !strings.Contains(err.Error(), "unresolved reference ${MISSING}") {
+			t.Errorf("expected unresolved reference error, got: %s", err)
+		}
+	})
+
+	t.Run("unterminated reference", func(t *testing.T) {
+		l := newLoader(map[string]string{"DB_URL": "${HOST"})
+
+		if _, err := l.Load(); err == nil {
+			t.Error("expected error for unterminated reference")
+		}
+	})
+
+	t.Run("environment variable reference", func(t *testing.T) {
+		t.Setenv("CONFIGLY_TEST_DB_HOST", "db.internal")
+		l := newLoader(map[string]string{"DB_URL": "postgres://${env:CONFIGLY_TEST_DB_HOST}/app"})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.DBURL != "postgres://db.internal/app" {
+			t.Errorf("expected DB_URL to be postgres://db.internal/app, got: %s", cfg.DBURL)
+		}
+	})
+
+	t.Run("disabled by default", func(t *testing.T) {
+		l, _ := New[interpolateConfig](LoaderConfig{
+			Sources: []sources.Source{&sources.MockSource{
+				SourceName: "test",
+				Values:     map[string]string{"DB_URL": "${HOST}"},
+			}},
+		})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.DBURL != "${HOST}" {
+			t.Errorf("expected DB_URL to be unexpanded, got: %s", cfg.DBURL)
+		}
+	})
+}
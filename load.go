@@ -3,7 +3,10 @@ package configly
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -15,42 +18,128 @@ import (
 const (
 	// defaultTagKey is the struct tag key used when none is specified in LoaderConfig.
 	defaultTagKey = "configly"
+	// defaultSourceName is the source name recorded in provenance for values
+	// taken from a tag's default option.
+	defaultSourceName = "default"
+	// defaultsSourceName is the name of the source built from LoaderConfig.Defaults.
+	defaultsSourceName = "defaults"
+	// nestedKeySeparator joins a nested struct's key prefix and its fields' keys.
+	nestedKeySeparator = sources.NestedKeySeparator
+	// maskedValue replaces secret values in logs and error messages.
+	maskedValue = "****"
+)
+
+// StrictMode controls how Load treats keys that are present in a source but
+// not consumed by any field of the configuration type.
+type StrictMode int
+
+const (
+	// StrictOff ignores unknown keys. This is the default.
+	StrictOff StrictMode = iota
+	// StrictWarn logs a warning for each unknown key.
+	StrictWarn
+	// StrictError fails Load with an error for each unknown key.
+	StrictError
 )
 
 // tagOptions represents parsed options from a struct field's tag.
 // It contains the configuration key, field index, validation constraints,
 // and whether the field is required.
 type tagOptions struct {
-	key          string // The key to look up in configuration sources
-	fieldIdx     int    // Index of the field in the struct
-	required     bool   // Whether this field must have a value
-	defaultValue string // Default value if not found in sources
-	min          *int64 // Minimum value for numeric types
-	max          *int64 // Maximum value for numeric types
-	minLen       *int   // Minimum length for string types
-	maxLen       *int   // Maximum length for string types
-	// TODO pattern
+	key          string            // The key to look up in configuration sources
+	fieldIndex   []int             // Index path of the field from the configuration type (see reflect.Value.FieldByIndex)
+	fieldPath    string            // Dotted Go path of the field, e.g. TLS.Cert
+	required     bool              // Whether this field must have a value
+	defaultValue string            // Default value if not found in sources
+	hasDefault   bool              // Whether a default was given, possibly empty
+	noExpand     bool              // Whether ${KEY} references are left unexpanded
+	min          *int64            // Minimum value for numeric types
+	max          *int64            // Maximum value for numeric types
+	minLen       *int              // Minimum length for string types
+	maxLen       *int              // Maximum length for string types
+	requiredIf   []keyCondition    // Keys whose values make this field required
+	requiredWith []string          // Keys whose presence makes this field required
+	excludedWith []string          // Keys whose presence forbids this field
+	comparisons  []fieldComparison // Comparisons against sibling fields
+	validators   []tagValidator    // Semantic validators such as url or hostname
+	custom       []tagValidator    // Custom validators registered in LoaderConfig.Validators
+	bytes        bool              // Whether an integer field is parsed as a byte size (see ParseByteSize)
+	sizeBounds   bool              // Whether min or max was written with a byte size unit
+	layout       string            // Layout for time.Time fields, or the name of a layout in namedLayouts
+	secret       bool              // Whether the value is masked like values from secret sources
+	reloadable   bool              // Whether a change can be applied without a restart (see Diff)
+	restart      bool              // Whether a change requires a restart; the default without reloadable
+	pattern      *regexp.Regexp    // Regular expression string values must match
+	oneOf        []string          // Values the field is restricted to
 }
 
+// Origin describes where the value of a configuration key came from.
+type Origin struct {
+	Source string // Name of the source that provided the value, or "default" for tag defaults
+	Key    string // Key the value was found under (e.g. DB_PASSWORD_FILE for _FILE indirection)
+	File   string // File the value was read from through indirection, if any
+	Secret bool   // Whether the source reported the value as a secret (see sources.SecretMarker) or the field is tagged secret
+}
+
+// Provenance maps configuration keys to the origin of their loaded values.
+// Keys that were not found in any source and have no default are absent.
+type Provenance map[string]Origin
+
 // Loader is a generic configuration loader for type T.
 // It retrieves values from multiple sources in priority order,
 // validates constraints, and populates a struct instance.
 type Loader[T any] struct {
 	tagKey  string           // The struct tag key to use for field configuration
 	sources []sources.Source // Configuration sources in priority order
-	logger  zerolog.Logger   // Logger for debugging and warnings
+	// explicit are the sources from LoaderConfig.Sources, without the source
+	// built from LoaderConfig.Defaults
+	explicit []sources.Source
+	strict   StrictMode // How unknown keys in sources are handled
+	// fileIndirection enables KEY_FILE lookups and file:// or @ value references
+	fileIndirection bool
+	maxFileSize     int64                    // Maximum size of files read through indirection
+	interpolate     bool                     // Whether ${KEY} references in values are expanded
+	validators      map[string]ValidatorFunc // Custom validators by tag option name
+	plan            *fieldPlan               // Parsed tags of T, shared between Loaders (see compilePlan)
+	logger          zerolog.Logger           // Logger for debugging and warnings
 }
 
 // LoaderConfig contains configuration options for creating a new Loader.
 type LoaderConfig struct {
 	TagKey  string           // The struct tag key to use (defaults to "configly" if empty)
 	Sources []sources.Source // Configuration sources in priority order (first source wins)
+	Strict  StrictMode       // How keys not consumed by any field are handled (defaults to StrictOff)
+	// FileIndirection enables reading values from files: when KEY is absent
+	// from a source but KEY_FILE is present, the value is read from the path
+	// KEY_FILE holds, and values of the form "file:///path" or "@/path" are
+	// replaced by the content of the referenced file. Defaults to false.
+	FileIndirection bool
+	// MaxFileSize is the maximum size in bytes of files read through
+	// FileIndirection (defaults to sources.DefaultMaxFileSize).
+	MaxFileSize int64
+	// Interpolate enables expansion of ${KEY} and ${KEY:-fallback} references
+	// in values and defaults. A literal $ is written as $$, and fields tagged
+	// noexpand are never expanded. Defaults to false.
+	Interpolate bool
+	// Defaults is an optional *T whose non-zero tagged fields act as the
+	// lowest-priority source, below all Sources but above tag defaults.
+	// Values are converted back into their string form and snapshotted when
+	// the Loader is created.
+	Defaults any
+	// Validators registers custom validators by tag option name, so a field
+	// tagged `configly:"REGION,awsRegion"` or `configly:"ID,tenantID=acme"`
+	// is checked by the function registered as awsRegion or tenantID, after
+	// the built-in constraints. Names must not collide with built-in options.
+	Validators map[string]ValidatorFunc
 }
 
 // New creates a new Loader instance for type T.
-// It validates that T is a struct type and that at least one source is provided.
+// It validates that T is a struct type and that at least one source is provided,
+// and parses T's struct tags once, so invalid tags are reported here rather
+// than by Load. Parsed tags are cached per type and shared by later Loaders.
 // The TagKey in LoaderConfig specifies which struct tag to use (defaults to "configly").
-// Returns an error if no sources are provided or if T is not a struct type.
+// Returns an error if no sources are provided, if T is not a struct type, or
+// if any of T's tags is invalid.
 func New[T any](cfg LoaderConfig) (*Loader[T], error) {
 	if len(cfg.Sources) == 0 {
 		return nil, errors.New("at least one source is required")
@@ -76,146 +165,463 @@ func New[T any](cfg LoaderConfig) (*Loader[T], error) {
 		tagKey = defaultTagKey
 	}
 
-	return &Loader[T]{
-		tagKey:  tagKey,
-		sources: cfg.Sources,
-		logger:  logger,
-	}, nil
+	if err := checkCustomValidators(cfg.Validators); err != nil {
+		return nil, err
+	}
+
+	maxFileSize := cfg.MaxFileSize
+	if maxFileSize <= 0 {
+		maxFileSize = sources.DefaultMaxFileSize
+	}
+
+	l := &Loader[T]{
+		tagKey:          tagKey,
+		sources:         cfg.Sources,
+		explicit:        cfg.Sources,
+		strict:          cfg.Strict,
+		fileIndirection: cfg.FileIndirection,
+		maxFileSize:     maxFileSize,
+		interpolate:     cfg.Interpolate,
+		validators:      maps.Clone(cfg.Validators),
+		logger:          logger,
+	}
+
+	plan, err := l.compilePlan()
+	if err != nil {
+		return nil, err
+	}
+	l.plan = plan
+
+	if cfg.Defaults != nil {
+		defaults, ok := cfg.Defaults.(*T)
+		if !ok || defaults == nil {
+			return nil, fmt.Errorf("invalid defaults: expected non-nil *%s, got %T", valType.Name(), cfg.Defaults)
+		}
+		source, err := l.defaultsSource(defaults)
+		if err != nil {
+			return nil, err
+		}
+		l.sources = append(slices.Clip(cfg.Sources), source)
+	}
+
+	return l, nil
 }
 
 // Load loads configuration values from sources into a new instance of type T.
-// It first parses all struct tags to identify fields and their constraints,
-// then retrieves values from sources in priority order (first source wins),
+// Using the fields and constraints parsed from T's tags in New, it
+// retrieves values from sources in priority order (first source wins),
 // applies defaults when values are not found, and validates all constraints.
 // Returns a fully populated and validated configuration instance or an error
 // containing all validation failures joined together.
 func (l *Loader[T]) Load() (*T, error) {
+	cfg, _, err := l.LoadWithProvenance()
+	return cfg, err
+}
+
+// LoadWithProvenance loads configuration like Load and additionally reports
+// where each loaded value came from: the source name, the key it was found
+// under, and the file it was read from when resolved through file indirection.
+func (l *Loader[T]) LoadWithProvenance() (*T, Provenance, error) {
 	var cfg T
-	val := reflect.ValueOf(&cfg).Elem()
-	typ := val.Type()
-	// parse all tags first, so that if there are any invalid/inproperly formatted
-	// tags, we can return all errors in one before attempting to fetch values from
-	// the sources which adds unnecessary runtime (trade-off is that the generic T
-	// must have valid tags before the user knows if there are any issues with the
-	// actual values stored in the sources)
-	tagOpts, err := l.parseAllTags(typ.NumField(), val)
+	provenance, loadErrors := l.load(reflect.ValueOf(&cfg).Elem(), l.plan.fields, false)
+	validationErrors := append(l.checkUnknownKeys(), loadErrors...)
+	if len(validationErrors) > 0 {
+		return nil, nil, errors.Join(validationErrors...)
+	}
+
+	return &cfg, provenance, nil
+}
+
+// LoadInto loads configuration like Load into dst, an existing instance of T.
+// Only fields that have a value, from a source or a tag default, are set;
+// other fields keep the values dst held before, so configuration set in code
+// can be layered under the loaded values. A non-zero field of dst takes
+// precedence over LoaderConfig.Defaults and its tag default, satisfies
+// required, and counts as
+// having a value for the cross-field rules.
+// dst is left unchanged if loading fails.
+// Returns an error containing all validation failures joined together.
+func (l *Loader[T]) LoadInto(dst *T) error {
+	if dst == nil {
+		return errors.New("destination must not be nil")
+	}
+	cfg := *dst
+	_, loadErrors := l.load(reflect.ValueOf(&cfg).Elem(), l.plan.fields, true)
+	validationErrors := append(l.checkUnknownKeys(), loadErrors...)
+	if len(validationErrors) > 0 {
+		return errors.Join(validationErrors...)
+	}
+
+	*dst = cfg
+	return nil
+}
+
+// LoadFields refreshes only the fields of dst named by fieldPaths, loading
+// them like LoadInto. A path is the dotted Go path of a field, e.g.
+// "LogLevel" or "TLS.Cert", or of a nested struct, which selects all of its
+// fields. Fields that are not selected are left as they are, but the
+// cross-field rules and Validate methods are applied to the whole of dst.
+// dst is left unchanged if loading fails.
+// Returns an error for unknown paths, or all validation failures joined
+// together.
+func (l *Loader[T]) LoadFields(dst *T, fieldPaths ...string) error {
+	if dst == nil {
+		return errors.New("destination must not be nil")
+	}
+	fields, err := l.selectFields(fieldPaths)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	cfg := *dst
+	if _, loadErrors := l.load(reflect.ValueOf(&cfg).Elem(), fields, true); len(loadErrors) > 0 {
+		return errors.Join(loadErrors...)
+	}
+
+	*dst = cfg
+	return nil
+}
+
+// selectFields returns the tagged fields matching fieldPaths (see
+// LoadFields), in declaration order.
+// Returns an error naming every path that matches no tagged field.
+func (l *Loader[T]) selectFields(fieldPaths []string) ([]tagOptions, error) {
+	var pathErrors []error
+	selected := make(map[string]bool, len(fieldPaths))
+	for _, path := range fieldPaths {
+		matched := false
+		for _, opts := range l.plan.fields {
+			if opts.fieldPath == path || strings.HasPrefix(opts.fieldPath, path+".") {
+				selected[opts.key] = true
+				matched = true
+			}
+		}
+		if !matched {
+			pathErrors = append(pathErrors, fmt.Errorf("unknown field path %q", path))
+		}
+	}
+	if len(pathErrors) > 0 {
+		return nil, errors.Join(pathErrors...)
 	}
 
+	var fields []tagOptions
+	for _, opts := range l.plan.fields {
+		if selected[opts.key] {
+			fields = append(fields, opts)
+		}
+	}
+	return fields, nil
+}
+
+// load resolves, sets, and validates fields of val, then applies the
+// cross-field rules and Validate methods to all of val. When preserve is set,
+// fields that are non-zero in val are only overwritten by values from
+// LoaderConfig.Sources, not by LoaderConfig.Defaults or tag defaults, and
+// count as having a value for the cross-field rules (see LoadInto).
+// Returns the provenance of the loaded fields and any validation errors.
+func (l *Loader[T]) load(val reflect.Value, tagOpts []tagOptions, preserve bool) (Provenance, []error) {
+	fields := l.plan.byKey
+	provenance := make(Provenance, len(tagOpts))
 	var validationErrors []error
 	for _, opts := range tagOpts {
-		value, sourceName, found := l.getValueFromSources(opts.key)
+		srcs := l.sources
+		if preserve && !val.FieldByIndex(opts.fieldIndex).IsZero() {
+			srcs = l.explicit
+		}
+		value, origin, found, err := l.resolveValueFrom(srcs, opts.key)
+		if err != nil {
+			validationErrors = append(validationErrors, err)
+			continue
+		}
+
+		if !found && preserve && !val.FieldByIndex(opts.fieldIndex).IsZero() {
+			continue
+		}
+
 		if !found && opts.required {
 			validationErrors = append(validationErrors, fmt.Errorf("required value %s not found in provided sources", opts.key))
 			continue
 		}
 
-		if !found && opts.defaultValue != "" {
+		if !found && opts.hasDefault {
 			value = opts.defaultValue
+			origin = Origin{Source: defaultSourceName, Key: opts.key}
 			found = true
 		}
 
 		if !found {
 			continue
 		}
+		origin.Secret = origin.Secret || opts.secret
 
-		fieldValue := val.Field(opts.fieldIdx)
-		if err := l.setField(&fieldValue, value); err != nil {
-			validationErrors = append(validationErrors, fmt.Errorf("error setting %s (source %s): %w", opts.key, sourceName, err))
+		if l.interpolate && !opts.noExpand {
+			value, err = l.expand(value, fields, []string{opts.key})
+			if err != nil {
+				validationErrors = append(validationErrors, fmt.Errorf("error expanding %s (source %s): %w", opts.key, origin.Source, err))
+				continue
+			}
+		}
+
+		fieldValue := val.FieldByIndex(opts.fieldIndex)
+		if err := l.setField(&fieldValue, value, opts); err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("error setting %s (source %s): %w", opts.key, origin.Source, redactError(err, value, origin.Secret)))
 			continue
 		}
+		provenance[opts.key] = origin
 
 		err = l.validateField(fieldValue, opts)
 		if err != nil {
-			validationErrors = append(validationErrors, err)
+			validationErrors = append(validationErrors, redactError(err, value, origin.Secret))
 		}
 	}
 
-	if len(validationErrors) > 0 {
-		return nil, errors.Join(validationErrors...)
+	present := provenance
+	if preserve {
+		present = maps.Clone(provenance)
+		for _, opts := range l.plan.fields {
+			if _, ok := present[opts.key]; !ok && !val.FieldByIndex(opts.fieldIndex).IsZero() {
+				present[opts.key] = Origin{}
+			}
+		}
+	}
+	validationErrors = append(validationErrors, l.checkFieldRules(val, present)...)
+	validationErrors = append(validationErrors, validateStructs(val, l.plan.validated)...)
+	return provenance, validationErrors
+}
+
+// checkUnknownKeys reports keys held by sources whose keys are all meant for
+// the loader (see sources.StrictKeyLister) but that are not consumed by any
+// field (see isKnownKey).
+// In StrictWarn mode each unknown key is logged and no errors are returned;
+// in StrictError mode an error is returned for each unknown key.
+func (l *Loader[T]) checkUnknownKeys() []error {
+	if l.strict == StrictOff {
+		return nil
+	}
+
+	var unknownErrors []error
+	for _, source := range l.sources {
+		lister, ok := source.(sources.StrictKeyLister)
+		if !ok {
+			continue
+		}
+		for _, key := range lister.StrictKeys() {
+			if l.isKnownKey(key) {
+				continue
+			}
+			if l.strict == StrictWarn {
+				l.logger.Warn().Str("source", source.Name()).Str("key", key).Msg("unknown key")
+				continue
+			}
+			unknownErrors = append(unknownErrors, fmt.Errorf("unknown key %s in source %s", key, source.Name()))
+		}
 	}
+	return unknownErrors
+}
 
-	return &cfg, nil
+// isKnownKey reports whether key is consumed by a field: it is a field's key
+// or, with file indirection, a field's key followed by fileKeySuffix.
+func (l *Loader[T]) isKnownKey(key string) bool {
+	if _, known := l.plan.byKey[key]; known {
+		return true
+	}
+	if fieldKey, isFileKey := strings.CutSuffix(key, fileKeySuffix); isFileKey && l.fileIndirection {
+		_, known := l.plan.byKey[fieldKey]
+		return known
+	}
+	return false
 }
 
 // parseAllTags parses struct tags for all fields in the configuration type.
-// It skips unexported fields and fields without tags. If any tag has invalid
-// formatting (e.g., invalid min/max values), all parsing errors are joined
-// and returned together. Returns a slice of tagOptions for valid tagged fields.
+// It skips unexported fields and fields without tags. Fields holding nested
+// structs are parsed recursively (see parseStructTags). If any tag has invalid
+// formatting (e.g., invalid min/max values) or a rule references an unknown
+// key or field, all parsing errors are joined and returned together.
+// Returns a slice of tagOptions for valid tagged fields.
 func (l *Loader[T]) parseAllTags(numFields int, val reflect.Value) ([]tagOptions, error) {
+	allOpts, parseErrors := l.parseStructTags(val.Type(), numFields, nil, "", "")
+	if len(parseErrors) == 0 {
+		parseErrors = l.resolveRules(val.Type(), allOpts)
+	}
+
+	if len(parseErrors) > 0 {
+		return nil, errors.Join(parseErrors...)
+	}
+
+	return allOpts, nil
+}
+
+// parseStructTags parses the tags of the first numFields fields of typ, a
+// struct reached through index from the configuration type.
+// Exported fields holding nested structs are descended into: when the field
+// is tagged, its tag names a key prefix, so a field tagged HOST inside a
+// struct field tagged DB is loaded from DB_HOST.
+// Returns the parsed options and any parsing errors.
+func (l *Loader[T]) parseStructTags(typ reflect.Type, numFields int, index []int, keyPrefix, pathPrefix string) ([]tagOptions, []error) {
 	var parseErrors []error
 	var allOpts []tagOptions
 	for idx := range numFields {
-		field := val.Type().Field(idx)
-		fieldValue := val.Field(idx)
+		field := typ.Field(idx)
 
-		if !fieldValue.CanSet() {
+		if !field.IsExported() {
 			l.logger.Debug().
 				Str("key", field.Name).
 				Msg("skipping unexported field")
 			continue
 		}
 
+		fieldIndex := append(slices.Clip(index), idx)
+		fieldPath := pathPrefix + field.Name
 		tag := field.Tag.Get(l.tagKey)
+		if isNestedStruct(field.Type) {
+			prefix, options, hasOptions := strings.Cut(tag, ",")
+			if hasOptions {
+				parseErrors = append(parseErrors, fmt.Errorf("invalid tag for nested struct %s: options are not supported: %s", fieldPath, options))
+				continue
+			}
+			if prefix != "" {
+				prefix = keyPrefix + prefix + nestedKeySeparator
+			} else {
+				prefix = keyPrefix
+			}
+			nestedOpts, nestedErrors := l.parseStructTags(field.Type, field.Type.NumField(), fieldIndex, prefix, fieldPath+".")
+			allOpts = append(allOpts, nestedOpts...)
+			parseErrors = append(parseErrors, nestedErrors...)
+			continue
+		}
+
 		if tag == "" {
 			l.logger.Debug().
-				Str("field", field.Name).
+				Str("field", fieldPath).
 				Msgf("no %s tag found, skipping", l.tagKey)
 			continue
 		}
 
 		tagOpts, tagWarnings := l.parseTag(tag)
+		tagOpts.key = keyPrefix + tagOpts.key
+		tagWarnings = append(tagWarnings, checkValidatorKinds(tagOpts, elemType(field.Type))...)
+		tagWarnings = append(tagWarnings, checkBytesOption(tagOpts, elemType(field.Type))...)
+		tagWarnings = append(tagWarnings, checkLayoutOption(tagOpts, elemType(field.Type))...)
+		tagWarnings = append(tagWarnings, checkValueOptions(tagOpts, elemType(field.Type))...)
+		if len(tagWarnings) == 0 {
+			tagWarnings = l.checkDefault(tagOpts, field.Type)
+		}
 		if len(tagWarnings) > 0 {
 			parseErrors = append(parseErrors, tagWarnings...)
 		} else {
-			tagOpts.fieldIdx = idx
+			tagOpts.fieldIndex = fieldIndex
+			tagOpts.fieldPath = fieldPath
 			allOpts = append(allOpts, tagOpts)
 		}
 	}
 
-	if len(parseErrors) > 0 {
-		return nil, errors.Join(parseErrors...)
-	}
+	return allOpts, parseErrors
+}
 
-	return allOpts, nil
+// isNestedStruct reports whether typ is a struct holding nested configuration
+// rather than a single value.
+func isNestedStruct(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && typ != timeType
 }
 
 // parseTag parses a single struct tag string into tagOptions.
 // Tag format: "key,option1,option2=value"
-// Supported options: required, default=value, min=int, max=int, minLen=int, maxLen=int
-// Returns the parsed options and a slice of errors for any invalid option values.
-// Whitespace around options is automatically trimmed.
+// Supported options: required, noexpand, bytes, secret, reloadable, restart, default=value, layout=value, min=int, max=int, minLen=int, maxLen=int,
+// pattern=regexp, oneof=value..., required_if=KEY:value, required_with=KEY..., excluded_with=KEY..., and
+// gtfield, gtefield, ltfield, ltefield=Field, the built-in validators, and
+// custom validators registered in LoaderConfig.Validators.
+// Option values may be single-quoted to include commas (see splitTag).
+// Returns the parsed options and a slice of errors for any invalid or unknown
+// option.
+// Whitespace around unquoted options is automatically trimmed.
 func (l *Loader[T]) parseTag(tag string) (tagOptions, []error) {
 	tagLogger := l.logger.With().Str("func", "parseTag").Str("tag", tag).Logger()
-	parts := strings.Split(tag, ",")
+	parts, err := splitTag(tag)
+	if err != nil {
+		tagLogger.Warn().Err(err).Send()
+		return tagOptions{}, []error{err}
+	}
 	tagLogger.Debug().Strs("parts", parts).Send()
 	opts := tagOptions{
 		key: parts[0],
 	}
 	var warnings []error
 	for _, part := range parts[1:] {
-		part = strings.TrimSpace(part)
 		switch {
 		case part == "required":
 			opts.required = true
+		case part == "noexpand":
+			opts.noExpand = true
+		case part == "bytes":
+			opts.bytes = true
+		case part == "secret":
+			opts.secret = true
+		case part == "reloadable":
+			opts.reloadable = true
+		case part == "restart":
+			opts.restart = true
 		case strings.HasPrefix(part, "default="):
 			opts.defaultValue = strings.TrimPrefix(part, "default=")
+			opts.hasDefault = true
+		case strings.HasPrefix(part, "layout="):
+			opts.layout = strings.TrimPrefix(part, "layout=")
+		case strings.HasPrefix(part, "pattern="):
+			if re, err := regexp.Compile(strings.TrimPrefix(part, "pattern=")); err != nil {
+				warning := fmt.Errorf("invalid pattern: %w", err)
+				warnings = append(warnings, warning)
+				tagLogger.Warn().Err(warning).Send()
+			} else {
+				opts.pattern = re
+			}
+		case strings.HasPrefix(part, "oneof="):
+			if values := strings.Fields(strings.TrimPrefix(part, "oneof=")); len(values) == 0 {
+				warning := errors.New("invalid oneof value: expected at least one value")
+				warnings = append(warnings, warning)
+				tagLogger.Warn().Err(warning).Send()
+			} else {
+				opts.oneOf = values
+			}
+		case strings.HasPrefix(part, "required_if="):
+			key, value, found := strings.Cut(strings.TrimPrefix(part, "required_if="), ":")
+			if !found || key == "" {
+				warning := fmt.Errorf("invalid required_if value %q: expected KEY:value", part)
+				warnings = append(warnings, warning)
+				tagLogger.Warn().Err(warning).Send()
+			} else {
+				opts.requiredIf = append(opts.requiredIf, keyCondition{key: key, value: value})
+			}
+		case strings.HasPrefix(part, "required_with="):
+			opts.requiredWith = append(opts.requiredWith, strings.Fields(strings.TrimPrefix(part, "required_with="))...)
+		case strings.HasPrefix(part, "excluded_with="):
+			opts.excludedWith = append(opts.excludedWith, strings.Fields(strings.TrimPrefix(part, "excluded_with="))...)
+		case isComparisonOption(part):
+			op, field, _ := strings.Cut(part, "=")
+			opts.comparisons = append(opts.comparisons, fieldComparison{op: op, field: field})
+		case isValidatorOption(part):
+			v, err := parseValidator(part)
+			if err != nil {
+				warning := fmt.Errorf("invalid validator: %w", err)
+				warnings = append(warnings, warning)
+				tagLogger.Warn().Err(warning).Send()
+			} else {
+				opts.validators = append(opts.validators, v)
+			}
 		case strings.HasPrefix(part, "min="):
-			if val, err := parseMinMax("min", part); err != nil {
+			if val, isSize, err := parseMinMax("min", part); err != nil {
 				warning := fmt.Errorf("invalid minimum value: %w", err)
 				warnings = append(warnings, warning)
 				tagLogger.Warn().Err(warning).Send()
 			} else {
 				opts.min = &val
+				opts.sizeBounds = opts.sizeBounds || isSize
 			}
 		case strings.HasPrefix(part, "max="):
-			if val, err := parseMinMax("max", part); err != nil {
+			if val, isSize, err := parseMinMax("max", part); err != nil {
 				warning := fmt.Errorf("invalid maximum value: %w", err)
 				warnings = append(warnings, warning)
 				tagLogger.Warn().Err(warning).Send()
 			} else {
 				opts.max = &val
+				opts.sizeBounds = opts.sizeBounds || isSize
 			}
 		case strings.HasPrefix(part, "minLen="):
 			if val, err := parseLen("minLen", part); err != nil {
@@ -233,21 +639,43 @@ func (l *Loader[T]) parseTag(tag string) (tagOptions, []error) {
 			} else {
 				opts.maxLen = &val
 			}
+		case part == "":
+			// Allow empty options, e.g. a trailing comma.
+		default:
+			name, param, _ := strings.Cut(part, "=")
+			if _, ok := l.validators[name]; ok {
+				opts.custom = append(opts.custom, tagValidator{name: name, param: param})
+			} else {
+				warning := fmt.Errorf("unknown tag option %q", part)
+				warnings = append(warnings, warning)
+				tagLogger.Warn().Err(warning).Send()
+			}
 		}
 	}
+	if opts.reloadable && opts.restart {
+		warning := errors.New("reloadable and restart are mutually exclusive")
+		warnings = append(warnings, warning)
+		tagLogger.Warn().Err(warning).Send()
+	}
 	return opts, warnings
 }
 
 // parseMinMax parses a min or max value from a tag option part.
-// The part should be in the format "min=123" or "max=456".
-// Returns the parsed int64 value or an error if parsing fails.
-func parseMinMax(prefixKey, part string) (int64, error) {
+// The part should be in the format "min=123", "max=0x1F", or, for byte
+// sizes, "max=64MiB".
+// Returns the parsed int64 value, whether it was written as a byte size with
+// a unit, or an error if parsing fails.
+func parseMinMax(prefixKey, part string) (int64, bool, error) {
 	str := strings.TrimPrefix(part, fmt.Sprintf("%s=", prefixKey))
-	val, err := strconv.ParseInt(str, 10, 64)
-	if err != nil {
-		return 0, err
+	val, err := parseInteger(str, 64)
+	if err == nil {
+		return val, false, nil
 	}
-	return val, nil
+	size, sizeErr := ParseByteSize(str)
+	if sizeErr != nil {
+		return 0, false, err
+	}
+	return int64(size), true, nil
 }
 
 // parseLen parses a minLen or maxLen value from a tag option part.
@@ -262,69 +690,182 @@ func parseLen(prefixKey, part string) (int, error) {
 	return val, nil
 }
 
-// getValueFromSources retrieves a value for the given key from configured sources.
+// getValueFromSources retrieves a value for the given key from srcs.
 // Sources are checked in order, and the first source that returns a value wins.
 // Sources that return errors are logged and skipped.
-// Returns the value, the source name it came from, and whether a value was found.
-func (l *Loader[T]) getValueFromSources(key string) (string, string, bool) {
-	logger := l.logger.With().Str("func", "getValueFromSources").Str("key", key).Logger()
-	for _, source := range l.sources {
-		val, found, err := source.GetValue(key)
-		if err != nil {
-			logger.Warn().Str("source", source.Name()).Err(err)
-			continue
+// Returns the value, the origin it came from, and whether a value was found.
+func (l *Loader[T]) getValueFromSources(srcs []sources.Source, key string) (string, Origin, bool) {
+	for _, source := range srcs {
+		if val, found := l.getValueFromSource(source, key); found {
+			return val, sourceOrigin(source, key), true
+		}
+	}
+	return "", Origin{}, false
+}
+
+// sourceOrigin returns the origin of a value found under key in source.
+// Wrapped sources (see sources.Wrapper) are unwrapped, so the origin names
+// the source and key that actually hold the value.
+func sourceOrigin(source sources.Source, key string) Origin {
+	for {
+		wrapper, ok := source.(sources.Wrapper)
+		if !ok {
+			break
 		}
-		if found {
-			logger.Debug().Str("source", source.Name()).Msgf("found value %s", val)
-			return val, source.Name(), true
+		underlying, underlyingKey, ok := wrapper.Underlying(key)
+		if !ok {
+			break
 		}
+		source, key = underlying, underlyingKey
+	}
+	marker, ok := source.(sources.SecretMarker)
+	return Origin{Source: source.Name(), Key: key, Secret: ok && marker.IsSecret(key)}
+}
+
+// getValueFromSource retrieves a value for the given key from a single source.
+// A source error is logged and treated as the value not being found.
+func (l *Loader[T]) getValueFromSource(source sources.Source, key string) (string, bool) {
+	val, found, err := source.GetValue(key)
+	if err != nil {
+		l.logger.Warn().Str("func", "getValueFromSource").Str("key", key).Str("source", source.Name()).
+			Err(err).Msg("error getting value from source")
+		return "", false
+	}
+	if !found {
+		return "", false
+	}
+	if event := l.logger.Debug(); event.Enabled() {
+		event.Str("func", "getValueFromSource").Str("key", key).Str("source", source.Name()).
+			Msgf("found value %s", maskValue(val, sourceOrigin(source, key).Secret || l.plan.byKey[key].secret))
+	}
+	return val, true
+}
+
+// maskValue returns val, or a mask if it is a secret.
+func maskValue(val string, secret bool) string {
+	if secret {
+		return maskedValue
+	}
+	return val
+}
+
+// redactError replaces occurrences of a secret value in err's message with a
+// mask, so parse and validation errors do not leak it. Non-secret errors are
+// returned unchanged.
+func redactError(err error, val string, secret bool) error {
+	if !secret || val == "" || !strings.Contains(err.Error(), val) {
+		return err
 	}
-	return "", "", false
+	return errors.New(strings.ReplaceAll(err.Error(), val, maskedValue))
 }
 
 // setField sets a struct field value by parsing a string value into the appropriate type.
-// Supported types: string, all int types, all uint types, all float types, bool, and time.Duration.
-// For time.Duration, the string must be in a format parseable by time.ParseDuration (e.g., "5s", "1h30m").
+// Supported types: string, all int types, all uint types, all float types, bool, time.Duration,
+// ByteSize, time.Time, *time.Location, and slices and maps of these (see setSlice and setMap).
+// For time.Duration, the string must be in a format parseable by time.ParseDuration (e.g., "5s", "1h30m"),
+// or use the d and w units (see parseDuration). Booleans accept yes/no, on/off, and
+// enabled/disabled (see parseBool). time.Time values are parsed with the layout option
+// (RFC3339 by default), and *time.Location values are IANA time zone names.
+// Integers may use digit separators and base prefixes (see parseInteger); ByteSize fields and
+// integer fields tagged bytes accept units (see ParseByteSize).
+// Numbers are parsed for the field's bit size, and values the field cannot represent are
+// rejected with the representable range rather than wrapped (see rangeError and parseFloat).
 // Returns an error if the string cannot be parsed into the field's type.
-func (l *Loader[T]) setField(value *reflect.Value, strVal string) error {
+func (l *Loader[T]) setField(value *reflect.Value, strVal string, opts tagOptions) error {
+	switch value.Type() {
+	case timeType:
+		layout := timeLayout(opts)
+		t, err := time.Parse(layout, strVal)
+		if err != nil {
+			return fmt.Errorf("invalid time (layout %s): %w", layout, err)
+		}
+		value.Set(reflect.ValueOf(t))
+		return nil
+	case locationType:
+		loc, err := time.LoadLocation(strVal)
+		if err != nil || strVal == "" {
+			return fmt.Errorf("invalid time zone %q", strVal)
+		}
+		value.Set(reflect.ValueOf(loc))
+		return nil
+	}
+
 	switch value.Kind() {
 	case reflect.String:
 		value.SetString(strVal)
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		if value.Type() == reflect.TypeOf(time.Duration(0)) {
-			duration, err := time.ParseDuration(strVal)
+		if value.Type() == durationType {
+			duration, err := parseDuration(strVal)
 			if err != nil {
 				return fmt.Errorf("invalid duration: %w", err)
 			}
 			value.SetInt(int64(duration))
 			return nil
 		}
-		intVal, err := strconv.ParseInt(strVal, 10, 64)
+		if opts.bytes || value.Type() == byteSizeType {
+			size, err := ParseByteSize(strVal)
+			if err != nil {
+				return err
+			}
+			if value.OverflowInt(int64(size)) {
+				return rangeError(strVal, value.Type())
+			}
+			value.SetInt(int64(size))
+			return nil
+		}
+		intVal, err := parseInteger(strVal, value.Type().Bits())
+		if errors.Is(err, strconv.ErrRange) {
+			return rangeError(strVal, value.Type())
+		}
 		if err != nil {
 			return fmt.Errorf("invalid integer: %w", err)
 		}
 		value.SetInt(intVal)
 
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		uintVal, err := strconv.ParseUint(strVal, 10, 64)
+		if opts.bytes {
+			size, err := ParseByteSize(strVal)
+			if err != nil {
+				return err
+			}
+			if value.OverflowUint(uint64(size)) {
+				return rangeError(strVal, value.Type())
+			}
+			value.SetUint(uint64(size))
+			return nil
+		}
+		uintVal, err := parseUnsigned(strVal, value.Type().Bits())
+		if errors.Is(err, strconv.ErrRange) || isNegativeInteger(strVal) {
+			return rangeError(strVal, value.Type())
+		}
 		if err != nil {
 			return fmt.Errorf("invalid unsigned integer: %w", err)
 		}
 		value.SetUint(uintVal)
 
 	case reflect.Float32, reflect.Float64:
-		floatVal, err := strconv.ParseFloat(strVal, 64)
+		floatVal, err := parseFloat(strVal, value.Type().Bits())
+		if errors.Is(err, strconv.ErrRange) {
+			return rangeError(strVal, value.Type())
+		}
 		if err != nil {
 			return fmt.Errorf("invalid float: %w", err)
 		}
 		value.SetFloat(floatVal)
 
 	case reflect.Bool:
-		boolVal, err := strconv.ParseBool(strVal)
+		boolVal, err := parseBool(strVal)
 		if err != nil {
 			return fmt.Errorf("invalid boolean: %w", err)
 		}
 		value.SetBool(boolVal)
+
+	case reflect.Slice:
+		return l.setSlice(value, strVal, opts)
+
+	case reflect.Map:
+		return l.setMap(value, strVal, opts)
+
 	default:
 		return fmt.Errorf("unsupported field type: %s", value.Kind())
 	}
@@ -332,10 +873,14 @@ func (l *Loader[T]) setField(value *reflect.Value, strVal string) error {
 }
 
 // validateField validates a field value against the constraints specified in its tag options.
-// For strings: validates minLen and maxLen if specified.
-// For integers (signed and unsigned): validates min and max if specified.
+// For strings: validates minLen, maxLen, pattern, and oneof, then semantic validators such as url, if specified.
+// For integers (signed and unsigned): validates min, max, oneof, and port if specified.
+// For byte sizes: validates min and max, reporting sizes with units.
 // For floats: validates min and max if specified.
-// Other types (bool, etc.) have no validation constraints.
+// For slices and maps: validates minLen and maxLen against the number of elements, then
+// each element against the other constraints (see validateElements).
+// Other types (bool, etc.) have no built-in validation constraints.
+// Custom validators run last, in tag order, for fields of any type.
 // Returns an error describing the first constraint violation, or nil if all constraints are satisfied.
 func (l *Loader[T]) validateField(field reflect.Value, opts tagOptions) error {
 	switch field.Kind() {
@@ -350,9 +895,30 @@ func (l *Loader[T]) validateField(field reflect.Value, opts tagOptions) error {
 			return fmt.Errorf("string length %d exceeds maximum %d", strLen, *opts.maxLen)
 		}
 
+		if opts.pattern != nil && !opts.pattern.MatchString(str) {
+			return fmt.Errorf("value %q does not match pattern %s", str, opts.pattern)
+		}
+
+		if err := checkOneOf(field, opts); err != nil {
+			return err
+		}
+
+		for _, v := range opts.validators {
+			if err := stringValidators[v.name](str, v.param); err != nil {
+				return err
+			}
+		}
+
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
 		val := field.Int()
 
+		if opts.bytes || field.Type() == byteSizeType {
+			if err := checkByteSizeBounds(ByteSize(val), opts); err != nil {
+				return err
+			}
+			break
+		}
+
 		if opts.min != nil && val < *opts.min {
 			return fmt.Errorf("integer value %d is less than minimum %d", val, *opts.min)
 		}
@@ -361,9 +927,29 @@ func (l *Loader[T]) validateField(field reflect.Value, opts tagOptions) error {
 			return fmt.Errorf("integer value %d exceeds maximum %d", val, *opts.max)
 		}
 
+		if err := checkOneOf(field, opts); err != nil {
+			return err
+		}
+
+		if hasValidator(opts, "port") {
+			if val < 0 {
+				return fmt.Errorf("invalid port %d: must be between %d and %d", val, minPort, maxPort)
+			}
+			if err := validatePort(uint64(val)); err != nil {
+				return err
+			}
+		}
+
 	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
 		val := field.Uint()
 
+		if opts.bytes {
+			if err := checkByteSizeBounds(ByteSize(val), opts); err != nil {
+				return err
+			}
+			break
+		}
+
 		if opts.min != nil && val < uint64(*opts.min) {
 			return fmt.Errorf("unsigned integer value %d is less than minimum %d", val, *opts.min)
 		}
@@ -372,6 +958,16 @@ func (l *Loader[T]) validateField(field reflect.Value, opts tagOptions) error {
 			return fmt.Errorf("unsigned integer value %d exceeds maximum %d", val, *opts.max)
 		}
 
+		if err := checkOneOf(field, opts); err != nil {
+			return err
+		}
+
+		if hasValidator(opts, "port") {
+			if err := validatePort(val); err != nil {
+				return err
+			}
+		}
+
 	case reflect.Float32, reflect.Float64:
 		val := field.Float()
 
@@ -382,6 +978,17 @@ func (l *Loader[T]) validateField(field reflect.Value, opts tagOptions) error {
 		if opts.max != nil && val > float64(*opts.max) {
 			return fmt.Errorf("float value %f exceeds maximum %d", val, *opts.max)
 		}
+
+	case reflect.Slice, reflect.Map:
+		if err := l.validateElements(field, opts); err != nil {
+			return err
+		}
+	}
+
+	for _, v := range opts.custom {
+		if err := l.validators[v.name](field.Interface(), v.param); err != nil {
+			return fmt.Errorf("%s: %w", v.name, err)
+		}
 	}
 
 	return nil
@@ -2,7 +2,10 @@ package configly
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -242,9 +245,7 @@ func TestLoad(t *testing.T) {
 			Value int `configly:"value,min=abc"`
 		}
 		source := &sources.MockSource{SourceName: "test", Values: map[string]string{}}
-		l, _ := New[badConfig](LoaderConfig{Sources: []sources.Source{source}})
-
-		_, err := l.Load()
+		_, err := New[badConfig](LoaderConfig{Sources: []sources.Source{source}})
 		if err == nil {
 			t.Error("expected error for invalid tag format")
 		}
@@ -465,6 +466,16 @@ func TestParseTag(t *testing.T) {
 		}
 	})
 
+	t.Run("parse noexpand tag", func(t *testing.T) {
+		opts, errs := l.parseTag("my_key,noexpand")
+		if len(errs) > 0 {
+			t.Errorf("expected no errors, got: %v", errs)
+		}
+		if !opts.noExpand {
+			t.Error("expected noExpand to be true")
+		}
+	})
+
 	t.Run("parse min/max values", func(t *testing.T) {
 		opts, errs := l.parseTag("my_key,min=0,max=100")
 		if len(errs) > 0 {
@@ -559,12 +570,17 @@ func TestParseAllTags(t *testing.T) {
 		var cfg mixedConfig
 		val := reflect.ValueOf(&cfg).Elem()
 		typ := val.Type()
-		l, _ := New[mixedConfig](LoaderConfig{Sources: []sources.Source{&sources.MockSource{SourceName: "test"}}})
+		l := &Loader[mixedConfig]{tagKey: defaultTagKey, logger: getBaseLogger()}
 
 		_, err := l.parseAllTags(typ.NumField(), val)
 		if err == nil {
 			t.Error("expected error for invalid tag")
 		}
+
+		_, err = New[mixedConfig](LoaderConfig{Sources: []sources.Source{&sources.MockSource{SourceName: "test"}}})
+		if err == nil {
+			t.Error("expected New to report the invalid tag")
+		}
 	})
 }
 
@@ -580,15 +596,15 @@ func TestGetValueFromSources(t *testing.T) {
 		}
 		l, _ := New[validConfig](LoaderConfig{Sources: []sources.Source{source1, source2}})
 
-		val, sourceName, found := l.getValueFromSources("key")
+		val, origin, found := l.getValueFromSources(l.sources, "key")
 		if !found {
 			t.Error("expected value to be found")
 		}
 		if val != "value1" {
 			t.Errorf("expected value to be 'value1', got: %s", val)
 		}
-		if sourceName != "source1" {
-			t.Errorf("expected sourceName to be 'source1', got: %s", sourceName)
+		if origin.Source != "source1" {
+			t.Errorf("expected source to be 'source1', got: %s", origin.Source)
 		}
 	})
 
@@ -596,7 +612,7 @@ func TestGetValueFromSources(t *testing.T) {
 		source := &sources.MockSource{SourceName: "test", Values: map[string]string{}}
 		l, _ := New[validConfig](LoaderConfig{Sources: []sources.Source{source}})
 
-		_, _, found := l.getValueFromSources("nonexistent")
+		_, _, found := l.getValueFromSources(l.sources, "nonexistent")
 		if found {
 			t.Error("expected value not to be found")
 		}
@@ -609,7 +625,7 @@ func TestGetValueFromSources(t *testing.T) {
 		}
 		l, _ := New[validConfig](LoaderConfig{Sources: []sources.Source{source}})
 
-		_, _, found := l.getValueFromSources("key")
+		_, _, found := l.getValueFromSources(l.sources, "key")
 		if found {
 			t.Error("expected value not to be found when source has error")
 		}
@@ -988,3 +1004,361 @@ func TestValidateField(t *testing.T) {
 		}
 	})
 }
+
+func TestStrictMode(t *testing.T) {
+	newSource := func() *sources.MockSource {
+		return &sources.MockSource{
+			SourceName: "test",
+			Values:     map[string]string{"value": "v", "PROT": "8080"},
+		}
+	}
+
+	t.Run("off ignores unknown keys", func(t *testing.T) {
+		l, _ := New[validConfig](LoaderConfig{Sources: []sources.Source{newSource()}})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Errorf("expected err to be nil, got: %s", err)
+		}
+		if cfg == nil || cfg.Value != "v" {
+			t.Error("expected config to be loaded")
+		}
+	})
+
+	t.Run("warn loads despite unknown keys", func(t *testing.T) {
+		l, _ := New[validConfig](LoaderConfig{
+			Sources: []sources.Source{newSource()},
+			Strict:  StrictWarn,
+		})
+
+		cfg, err := l.Load()
+		if err != nil {
+			t.Errorf("expected err to be nil, got: %s", err)
+		}
+		if cfg == nil || cfg.Value != "v" {
+			t.Error("expected config to be loaded")
+		}
+	})
+
+	t.Run("error fails on unknown keys", func(t *testing.T) {
+		l, _ := New[validConfig](LoaderConfig{
+			Sources: []sources.Source{newSource()},
+			Strict:  StrictError,
+		})
+
+		cfg, err := l.Load()
+		if err == nil {
+			t.Fatal("expected error for unknown key")
+		}
+		if cfg != nil {
+			t.Error("expected config to be nil")
+		}
+		if !contains(err.Error(), "unknown key PROT in source test") {
+			t.Errorf("expected error to name the unknown key and source, got: %s", err)
+		}
+	})
+
+	t.Run("error aggregates with validation errors", func(t *testing.T) {
+		source := &sources.MockSource{SourceName: "test", Values: map[string]string{"PROT": "8080"}}
+		l, _ := New[validConfig](LoaderConfig{
+			Sources: []sources.Source{source},
+			Strict:  StrictError,
+		})
+
+		_, err := l.Load()
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		if !contains(err.Error(), "PROT") || !contains(err.Error(), "required value value") {
+			t.Errorf("expected both unknown key and required errors, got: %s", err)
+		}
+	})
+
+	t.Run("error accepts file indirection keys", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "value")
+		if err := os.WriteFile(path, []byte("from-file"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		source := &sources.MockSource{SourceName: "test", Values: map[string]string{"value_FILE": path, "other_FILE": path}}
+		l, _ := New[validConfig](LoaderConfig{
+			Sources:         []sources.Source{source},
+			Strict:          StrictError,
+			FileIndirection: true,
+		})
+
+		_, err := l.Load()
+		if err == nil || !contains(err.Error(), "unknown key other_FILE") || contains(err.Error(), "unknown key value_FILE") {
+			t.Errorf("expected only other_FILE to be unknown, got: %v", err)
+		}
+
+		l, _ = New[validConfig](LoaderConfig{Sources: []sources.Source{source}, Strict: StrictError})
+		if _, err := l.Load(); err == nil || !contains(err.Error(), "unknown key value_FILE") {
+			t.Errorf("expected value_FILE to be unknown without file indirection, got: %v", err)
+		}
+	})
+
+	t.Run("only sources whose keys are all meant for the loader are checked", func(t *testing.T) {
+		shared := sources.FromMap(map[string]string{"value": "v", "OTHER_APP_KEY": "x"})
+		file, err := sources.FromReader(strings.NewReader("PROT: 8080\n"), sources.FormatYAML, "config.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		l, _ := New[validConfig](LoaderConfig{
+			Sources: []sources.Source{sources.Fallback(file, shared)},
+			Strict:  StrictError,
+		})
+
+		_, err = l.Load()
+		if err == nil || !contains(err.Error(), "unknown key PROT") {
+			t.Errorf("expected unknown key PROT from the file, got: %v", err)
+		}
+		if err != nil && contains(err.Error(), "OTHER_APP_KEY") {
+			t.Errorf("expected keys of the map source to be skipped, got: %s", err)
+		}
+	})
+
+	t.Run("sources without key listing are skipped", func(t *testing.T) {
+		t.Setenv("CONFIGLY_STRICT_TEST_UNUSED", "1")
+		l, _ := New[configWithDefaults](LoaderConfig{
+			Sources: []sources.Source{sources.FromEnv()},
+			Strict:  StrictError,
+		})
+
+		if _, err := l.Load(); err != nil {
+			t.Errorf("expected err to be nil, got: %s", err)
+		}
+	})
+}
+
+func TestLoadWithProvenance(t *testing.T) {
+	source := &sources.MockSource{
+		SourceName: "test",
+		Values:     map[string]string{"host": "example.com"},
+	}
+	l, _ := New[configWithDefaults](LoaderConfig{Sources: []sources.Source{source}})
+
+	_, provenance, err := l.LoadWithProvenance()
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %s", err)
+	}
+	if origin := provenance["host"]; origin.Source != "test" || origin.Key != "host" {
+		t.Errorf("expected host to come from test, got: %+v", origin)
+	}
+	if origin := provenance["port"]; origin.Source != defaultSourceName {
+		t.Errorf("expected port to come from default, got: %+v", origin)
+	}
+}
+
+// secretSource is a MockSource that reports every value as secret.
+type secretSource struct {
+	sources.MockSource
+}
+
+func (s *secretSource) IsSecret(key string) bool {
+	return true
+}
+
+func TestLoadSecrets(t *testing.T) {
+	type secretConfig struct {
+		Password string `configly:"password"`
+		Pin      int    `configly:"pin"`
+	}
+
+	t.Run("mark secret values in provenance", func(t *testing.T) {
+		source := &secretSource{sources.MockSource{
+			SourceName: "vault",
+			Values:     map[string]string{"password": "s3cret"},
+		}}
+		l, _ := New[secretConfig](LoaderConfig{Sources: []sources.Source{source}})
+
+		cfg, provenance, err := l.LoadWithProvenance()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if cfg.Password != "s3cret" {
+			t.Errorf("expected password to be 's3cret', got: %s", cfg.Password)
+		}
+		if !provenance["password"].Secret {
+			t.Errorf("expected password to be marked secret, got: %+v", provenance["password"])
+		}
+	})
+
+	t.Run("redact secret values in errors", func(t *testing.T) {
+		source := &secretSource{sources.MockSource{
+			SourceName: "vault",
+			Values:     map[string]string{"pin": "hunter2"},
+		}}
+		l, _ := New[secretConfig](LoaderConfig{Sources: []sources.Source{source}})
+
+		_, err := l.Load()
+		if err == nil {
+			t.Fatal("expected error for invalid integer")
+		}
+		if strings.Contains(err.Error(), "hunter2") {
+			t.Errorf("expected secret value to be redacted, got: %s", err)
+		}
+		if !strings.Contains(err.Error(), "pin") {
+			t.Errorf("expected error to name the key, got: %s", err)
+		}
+	})
+}
+
+func TestLoadWithProvenance_Combinators(t *testing.T) {
+	env := &sources.MockSource{SourceName: "env", Values: map[string]string{"APP_host": "example.com"}}
+	l, _ := New[configWithDefaults](LoaderConfig{Sources: []sources.Source{
+		sources.Fallback(sources.Prefixed(env, "APP_"), sources.Static(map[string]string{"port": "9090"})),
+	}})
+
+	_, provenance, err := l.LoadWithProvenance()
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %s", err)
+	}
+	if origin := provenance["host"]; origin.Source != "env" || origin.Key != "APP_host" {
+		t.Errorf("expected host to come from env APP_host, got: %+v", origin)
+	}
+	if origin := provenance["port"]; origin.Source != "static" || origin.Key != "port" {
+		t.Errorf("expected port to come from static, got: %+v", origin)
+	}
+}
+
+// countingSource is a MockSource that counts GetValue calls.
+type countingSource struct {
+	sources.MockSource
+	calls int
+}
+
+func (s *countingSource) GetValue(key string) (string, bool, error) {
+	s.calls++
+	return s.MockSource.GetValue(key)
+}
+
+func TestLoadWithProvenance_CachedFallback(t *testing.T) {
+	primary := &countingSource{MockSource: sources.MockSource{SourceName: "remote", Values: map[string]string{"host": "example.com"}}}
+	secondary := &countingSource{MockSource: sources.MockSource{SourceName: "backup", Values: map[string]string{"port": "9090"}}}
+	l, _ := New[configWithDefaults](LoaderConfig{Sources: []sources.Source{
+		sources.Cached(sources.Fallback(primary, secondary), time.Hour),
+	}})
+
+	for range 3 {
+		_, provenance, err := l.LoadWithProvenance()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		if origin := provenance["host"]; origin.Source != "remote" {
+			t.Errorf("expected host to come from remote, got: %+v", origin)
+		}
+		if origin := provenance["port"]; origin.Source != "backup" {
+			t.Errorf("expected port to come from backup, got: %+v", origin)
+		}
+	}
+	// Each key is looked up once: host in the primary, port in both.
+	if primary.calls != 2 || secondary.calls != 1 {
+		t.Errorf("expected 2 primary and 1 secondary lookups, got: %d and %d", primary.calls, secondary.calls)
+	}
+}
+
+func TestLoadInto(t *testing.T) {
+	type serverConfig struct {
+		Host     string `configly:"HOST,default=localhost"`
+		Port     int    `configly:"PORT,default=8080,port"`
+		Token    string `configly:"TOKEN,required"`
+		LogLevel string `configly:"LOG_LEVEL,default=info"`
+		TLS      struct {
+			Cert string `configly:"CERT"`
+			Key  string `configly:"KEY,required_with=TLS_CERT"`
+		} `configly:"TLS"`
+		MinConns int `configly:"MIN_CONNS"`
+		MaxConns int `configly:"MAX_CONNS,gtefield=MinConns"`
+	}
+
+	t.Run("preserves existing values", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{"PORT": "9090", "LOG_LEVEL": "debug"})
+		l, err := New[serverConfig](LoaderConfig{Sources: []sources.Source{source}})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		cfg := serverConfig{Host: "app.internal", Port: 80, Token: "set-in-code", MinConns: 2}
+		if err := l.LoadInto(&cfg); err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if cfg.Host != "app.internal" || cfg.Token != "set-in-code" || cfg.MinConns != 2 {
+			t.Errorf("expected existing values to be preserved, got: %+v", cfg)
+		}
+		if cfg.Port != 9090 || cfg.LogLevel != "debug" {
+			t.Errorf("expected source values to override, got: %+v", cfg)
+		}
+
+		var empty serverConfig
+		empty.Token = "t"
+		if err := l.LoadInto(&empty); err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if empty.Host != "localhost" {
+			t.Errorf("expected tag default for zero field, got: %s", empty.Host)
+		}
+	})
+
+	t.Run("preserves existing values over programmatic defaults", func(t *testing.T) {
+		l, err := New[serverConfig](LoaderConfig{
+			Sources:  []sources.Source{sources.FromMap(map[string]string{"LOG_LEVEL": "debug"})},
+			Defaults: &serverConfig{Port: 8080, Host: "defaults.internal", LogLevel: "warn"},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		cfg := serverConfig{Port: 9000, Token: "t", LogLevel: "info"}
+		if err := l.LoadInto(&cfg); err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if cfg.Port != 9000 {
+			t.Errorf("expected existing port to be preserved, got: %d", cfg.Port)
+		}
+		if cfg.Host != "defaults.internal" || cfg.LogLevel != "debug" {
+			t.Errorf("expected defaults for zero fields and source values to override, got: %+v", cfg)
+		}
+	})
+
+	t.Run("leaves destination unchanged on error", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{"PORT": "0", "HOST": "example.com"})
+		l, _ := New[serverConfig](LoaderConfig{Sources: []sources.Source{source}})
+		cfg := serverConfig{Host: "app.internal", MinConns: 5, MaxConns: 1}
+		err := l.LoadInto(&cfg)
+		if err == nil {
+			t.Fatal("expected errors")
+		}
+		for _, expected := range []string{"required value TOKEN not found", "invalid port 0", "MAX_CONNS: value 1 must be greater than or equal to MinConns (5)"} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %s", expected, err)
+			}
+		}
+		if cfg.Host != "app.internal" {
+			t.Errorf("expected destination to be unchanged, got: %+v", cfg)
+		}
+		if err := l.LoadInto(nil); err == nil {
+			t.Error("expected error for nil destination")
+		}
+	})
+
+	t.Run("load fields", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{
+			"HOST": "new.example.com", "LOG_LEVEL": "warn", "TLS_CERT": "new.pem", "TLS_KEY": "new.key",
+		})
+		l, _ := New[serverConfig](LoaderConfig{Sources: []sources.Source{source}})
+		cfg := serverConfig{Host: "old.example.com", LogLevel: "info"}
+		cfg.TLS.Cert, cfg.TLS.Key = "old.pem", "old.key"
+		if err := l.LoadFields(&cfg, "LogLevel", "TLS"); err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if cfg.LogLevel != "warn" || cfg.TLS.Cert != "new.pem" || cfg.TLS.Key != "new.key" {
+			t.Errorf("expected selected fields to be refreshed, got: %+v", cfg)
+		}
+		if cfg.Host != "old.example.com" || cfg.Port != 0 {
+			t.Errorf("expected other fields to be left as they are, got: %+v", cfg)
+		}
+
+		err := l.LoadFields(&cfg, "LogLevel", "TLS.Chain", "Missing")
+		if err == nil || !strings.Contains(err.Error(), `unknown field path "TLS.Chain"`) || !strings.Contains(err.Error(), `unknown field path "Missing"`) {
+			t.Errorf("expected unknown path errors, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,285 @@
+package configly
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// day and week are the duration units parseDuration adds to those of
+	// time.ParseDuration.
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	locationType = reflect.TypeOf((*time.Location)(nil))
+	durationType = reflect.TypeOf(time.Duration(0))
+	byteSizeType = reflect.TypeOf(ByteSize(0))
+)
+
+// namedLayouts are the time layouts that a layout tag option may name
+// instead of spelling out.
+var namedLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"DateTime":    time.DateTime,
+	"DateOnly":    time.DateOnly,
+	"TimeOnly":    time.TimeOnly,
+	"Kitchen":     time.Kitchen,
+}
+
+// trueValues and falseValues are the accepted boolean spellings, lowercase.
+var (
+	trueValues  = []string{"1", "t", "true", "y", "yes", "on", "enabled"}
+	falseValues = []string{"0", "f", "false", "n", "no", "off", "disabled"}
+)
+
+// parseBool parses a boolean written as true/false, yes/no, on/off,
+// enabled/disabled, t/f, y/n, or 1/0, case-insensitively.
+func parseBool(s string) (bool, error) {
+	lower := strings.ToLower(s)
+	for i := range trueValues {
+		switch lower {
+		case trueValues[i]:
+			return true, nil
+		case falseValues[i]:
+			return false, nil
+		}
+	}
+	return false, fmt.Errorf("%q is not one of true/false, yes/no, on/off, enabled/disabled, or 1/0", s)
+}
+
+// parseDuration parses a duration like time.ParseDuration, additionally
+// accepting d (24h) and w (7d) units, e.g. "7d" or "1w2d12h".
+func parseDuration(s string) (time.Duration, error) {
+	if !strings.ContainsAny(s, "dw") {
+		return time.ParseDuration(s)
+	}
+
+	rest := strings.TrimLeft(s, "+-")
+	var converted strings.Builder
+	converted.WriteString(s[:len(s)-len(rest)])
+	for rest != "" {
+		numEnd := strings.IndexFunc(rest, func(r rune) bool { return !(r >= '0' && r <= '9' || r == '.') })
+		if numEnd <= 0 {
+			return 0, fmt.Errorf("time: invalid duration %q", s)
+		}
+		unitEnd := strings.IndexFunc(rest[numEnd:], func(r rune) bool { return r >= '0' && r <= '9' || r == '.' })
+		if unitEnd == -1 {
+			unitEnd = len(rest) - numEnd
+		}
+		number, unit := rest[:numEnd], rest[numEnd:numEnd+unitEnd]
+		rest = rest[numEnd+unitEnd:]
+
+		var scale time.Duration
+		switch unit {
+		case "d":
+			scale = day
+		case "w":
+			scale = week
+		default:
+			converted.WriteString(number + unit)
+			continue
+		}
+		n, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return 0, fmt.Errorf("time: invalid duration %q", s)
+		}
+		converted.WriteString(strconv.FormatFloat(n*scale.Hours(), 'f', -1, 64) + "h")
+	}
+
+	duration, err := time.ParseDuration(converted.String())
+	if err != nil {
+		return 0, fmt.Errorf("time: invalid duration %q", s)
+	}
+	return duration, nil
+}
+
+// maxExactFloat64 is the largest integer magnitude up to which every integer
+// is exactly representable as a float64.
+const maxExactFloat64 = 1 << 53
+
+// parseFloat parses a float for a field of bitSize bits, rounding directly to
+// float32 for 32-bit fields. Values beyond the field's range return an error
+// wrapping strconv.ErrRange. Integers that a float32 field cannot hold
+// exactly, such as 16777217, are rejected rather than silently rounded.
+func parseFloat(s string, bitSize int) (float64, error) {
+	f, err := strconv.ParseFloat(s, bitSize)
+	if err != nil || bitSize != 32 {
+		return f, err
+	}
+	exact, err := strconv.ParseFloat(s, 64)
+	if err == nil && exact == math.Trunc(exact) && math.Abs(exact) <= maxExactFloat64 && f != exact {
+		return 0, fmt.Errorf("%s cannot be represented exactly as float32 (nearest is %s)", s, strconv.FormatFloat(f, 'f', -1, 32))
+	}
+	return f, nil
+}
+
+// isNegativeInteger reports whether s is a negative integer literal, which
+// is out of range for unsigned fields.
+func isNegativeInteger(s string) bool {
+	_, err := parseInteger(s, 64)
+	return strings.HasPrefix(s, "-") && (err == nil || errors.Is(err, strconv.ErrRange))
+}
+
+// rangeError reports that str is out of range for a numeric field of typ,
+// naming the range the field can represent.
+func rangeError(str string, typ reflect.Type) error {
+	bits := typ.Bits()
+	switch numericKind(typ.Kind()) {
+	case "int":
+		maxInt := int64(^uint64(0) >> (65 - bits))
+		return fmt.Errorf("value %s out of range for %s (%d to %d)", str, typ.Kind(), -maxInt-1, maxInt)
+	case "uint":
+		return fmt.Errorf("value %s out of range for %s (0 to %d)", str, typ.Kind(), ^uint64(0)>>(64-bits))
+	default:
+		limit := math.MaxFloat64
+		if bits == 32 {
+			limit = math.MaxFloat32
+		}
+		return fmt.Errorf("value %s out of range for %s (%g to %g)", str, typ.Kind(), -limit, limit)
+	}
+}
+
+// timeLayout returns the layout used to parse a time.Time field: the layout
+// tag option, resolved through namedLayouts, or time.RFC3339.
+func timeLayout(opts tagOptions) string {
+	if opts.layout == "" {
+		return time.RFC3339
+	}
+	if layout, ok := namedLayouts[opts.layout]; ok {
+		return layout
+	}
+	return opts.layout
+}
+
+// checkLayoutOption reports a layout option on a field that is not a
+// time.Time.
+func checkLayoutOption(opts tagOptions, typ reflect.Type) []error {
+	if opts.layout != "" && typ != timeType {
+		return []error{fmt.Errorf("invalid tag for %s: layout is not supported for type %s", opts.key, typ)}
+	}
+	return nil
+}
+
+// elemType returns the element type of slice and map fields, whose tag
+// options apply to each element, and typ itself otherwise.
+func elemType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map {
+		return typ.Elem()
+	}
+	return typ
+}
+
+// splitList splits a comma-separated list, trimming whitespace around each
+// item. An empty string is an empty list.
+func splitList(str string) []string {
+	if strings.TrimSpace(str) == "" {
+		return nil
+	}
+	items := strings.Split(str, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
+
+// setSlice sets a slice field from a comma-separated list such as "a, b, c",
+// parsing each item with setField and the field's options. An empty string
+// yields an empty, non-nil slice.
+func (l *Loader[T]) setSlice(value *reflect.Value, strVal string, opts tagOptions) error {
+	items := splitList(strVal)
+	slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+	for i, item := range items {
+		elem := slice.Index(i)
+		if err := l.setField(&elem, item, opts); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	value.Set(slice)
+	return nil
+}
+
+// setMap sets a map field from a comma-separated list of key=value entries
+// such as "eu=10, us=20". Keys are parsed with setField, and values with
+// setField and the field's options. An empty string yields an empty, non-nil
+// map.
+// Returns an error for entries without "=" and for duplicate keys.
+func (l *Loader[T]) setMap(value *reflect.Value, strVal string, opts tagOptions) error {
+	items := splitList(strVal)
+	typ := value.Type()
+	m := reflect.MakeMapWithSize(typ, len(items))
+	for _, item := range items {
+		rawKey, rawValue, found := strings.Cut(item, "=")
+		if !found {
+			return fmt.Errorf("invalid map entry %q: expected key=value", item)
+		}
+		key := reflect.New(typ.Key()).Elem()
+		if err := l.setField(&key, strings.TrimSpace(rawKey), tagOptions{}); err != nil {
+			return fmt.Errorf("invalid map key %q: %w", rawKey, err)
+		}
+		if m.MapIndex(key).IsValid() {
+			return fmt.Errorf("duplicate map key %q", rawKey)
+		}
+		elem := reflect.New(typ.Elem()).Elem()
+		if err := l.setField(&elem, strings.TrimSpace(rawValue), opts); err != nil {
+			return fmt.Errorf("map key %q: %w", rawKey, err)
+		}
+		m.SetMapIndex(key, elem)
+	}
+	value.Set(m)
+	return nil
+}
+
+// validateElements checks the number of elements of a slice or map field
+// against minLen and maxLen, then each element, in order (map values by key),
+// against the remaining constraints.
+func (l *Loader[T]) validateElements(field reflect.Value, opts tagOptions) error {
+	n := field.Len()
+	if opts.minLen != nil && n < *opts.minLen {
+		return fmt.Errorf("%s length %d less than minimum %d", field.Kind(), n, *opts.minLen)
+	}
+	if opts.maxLen != nil && n > *opts.maxLen {
+		return fmt.Errorf("%s length %d exceeds maximum %d", field.Kind(), n, *opts.maxLen)
+	}
+
+	elemOpts := opts
+	elemOpts.minLen, elemOpts.maxLen, elemOpts.custom = nil, nil, nil
+	if field.Kind() == reflect.Slice {
+		for i := range n {
+			if err := l.validateField(field.Index(i), elemOpts); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		return nil
+	}
+	for _, key := range sortedMapKeys(field) {
+		if err := l.validateField(field.MapIndex(key), elemOpts); err != nil {
+			return fmt.Errorf("map key %v: %w", key.Interface(), err)
+		}
+	}
+	return nil
+}
+
+// sortedMapKeys returns the keys of a map value ordered by their formatted
+// value, so errors and formatted output are deterministic.
+func sortedMapKeys(m reflect.Value) []reflect.Value {
+	keys := m.MapKeys()
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		return cmp.Compare(fmt.Sprint(a.Interface()), fmt.Sprint(b.Interface()))
+	})
+	return keys
+}
@@ -0,0 +1,228 @@
+package configly
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zanedma/configly/sources"
+)
+
+func TestParseBool(t *testing.T) {
+	valid := map[string]bool{
+		"true": true, "TRUE": true, "t": true, "1": true, "yes": true, "Y": true, "on": true, "Enabled": true,
+		"false": false, "F": false, "0": false, "no": false, "n": false, "OFF": false, "disabled": false,
+	}
+	for str, expected := range valid {
+		val, err := parseBool(str)
+		if err != nil {
+			t.Errorf("%q: expected no error, got: %s", str, err)
+			continue
+		}
+		if val != expected {
+			t.Errorf("%q: expected %t, got: %t", str, expected, val)
+		}
+	}
+
+	for _, str := range []string{"", "maybe", "yess", "2", " yes"} {
+		if _, err := parseBool(str); err == nil {
+			t.Errorf("%q: expected error", str)
+		}
+	}
+}
+
+func TestParseDuration(t *testing.T) {
+	valid := map[string]time.Duration{
+		"30s":      30 * time.Second,
+		"1h30m":    90 * time.Minute,
+		"7d":       7 * day,
+		"1w":       week,
+		"1w2d12h":  week + 2*day + 12*time.Hour,
+		"1.5d":     36 * time.Hour,
+		"-1d":      -day,
+		"2d300ms":  2*day + 300*time.Millisecond,
+		"+0.5w30m": 84*time.Hour + 30*time.Minute,
+	}
+	for str, expected := range valid {
+		val, err := parseDuration(str)
+		if err != nil {
+			t.Errorf("%q: expected no error, got: %s", str, err)
+			continue
+		}
+		if val != expected {
+			t.Errorf("%q: expected %s, got: %s", str, expected, val)
+		}
+	}
+
+	for _, str := range []string{"d", "7", "7dd", "1x2d", "1..5d", "disabled"} {
+		_, err := parseDuration(str)
+		if err == nil {
+			t.Errorf("%q: expected error", str)
+			continue
+		}
+		if !strings.Contains(err.Error(), str) {
+			t.Errorf("%q: expected error naming the original value, got: %s", str, err)
+		}
+	}
+}
+
+func TestLoadTimes(t *testing.T) {
+	type windowConfig struct {
+		Start    time.Time      `configly:"START"`
+		Launch   time.Time      `configly:"LAUNCH,layout=DateOnly"`
+		Daily    time.Time      `configly:"DAILY,layout=15:04"`
+		Zone     *time.Location `configly:"ZONE,default=UTC"`
+		Retain   time.Duration  `configly:"RETAIN,default=2w"`
+		Maintain bool           `configly:"MAINTAIN"`
+	}
+
+	t.Run("valid values", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{
+			"START":    "2026-03-01T02:00:00+01:00",
+			"LAUNCH":   "2026-04-15",
+			"DAILY":    "03:30",
+			"ZONE":     "Europe/Berlin",
+			"MAINTAIN": "on",
+		})
+		l, _ := New[windowConfig](LoaderConfig{Sources: []sources.Source{source}})
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if !cfg.Start.Equal(time.Date(2026, 3, 1, 1, 0, 0, 0, time.UTC)) {
+			t.Errorf("expected start 2026-03-01T01:00:00Z, got: %s", cfg.Start)
+		}
+		if !cfg.Launch.Equal(time.Date(2026, 4, 15, 0, 0, 0, 0, time.UTC)) {
+			t.Errorf("expected launch 2026-04-15, got: %s", cfg.Launch)
+		}
+		if cfg.Daily.Hour() != 3 || cfg.Daily.Minute() != 30 {
+			t.Errorf("expected daily 03:30, got: %s", cfg.Daily)
+		}
+		if cfg.Zone == nil || cfg.Zone.String() != "Europe/Berlin" {
+			t.Errorf("expected zone Europe/Berlin, got: %v", cfg.Zone)
+		}
+		if cfg.Retain != 2*week || !cfg.Maintain {
+			t.Errorf("expected retain 2w and maintain true, got: %s, %t", cfg.Retain, cfg.Maintain)
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{
+			"START":  "2026-03-01",
+			"LAUNCH": "15/04/2026",
+			"ZONE":   "Mars/Olympus",
+		})
+		l, _ := New[windowConfig](LoaderConfig{Sources: []sources.Source{source}})
+		_, err := l.Load()
+		if err == nil {
+			t.Fatal("expected errors")
+		}
+		for _, expected := range []string{
+			"error setting START (source map): invalid time (layout 2006-01-02T15:04:05Z07:00)",
+			"error setting LAUNCH (source map): invalid time (layout 2006-01-02)",
+			`invalid time zone "Mars/Olympus"`,
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %s", expected, err)
+			}
+		}
+	})
+
+	t.Run("layout on non-time field", func(t *testing.T) {
+		type invalidConfig struct {
+			Name string `configly:"NAME,layout=DateOnly"`
+		}
+		_, err := New[invalidConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}})
+		if err == nil || !strings.Contains(err.Error(), "layout is not supported for type string") {
+			t.Errorf("expected layout error, got: %v", err)
+		}
+	})
+
+	t.Run("programmatic defaults", func(t *testing.T) {
+		berlin, err := time.LoadLocation("Europe/Berlin")
+		if err != nil {
+			t.Skipf("time zone database unavailable: %s", err)
+		}
+		defaults := &windowConfig{
+			Launch: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+			Zone:   berlin,
+		}
+		l, err := New[windowConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}, Defaults: defaults})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if !cfg.Launch.Equal(defaults.Launch) || cfg.Zone.String() != "Europe/Berlin" {
+			t.Errorf("expected defaults to round-trip, got: %s, %s", cfg.Launch, cfg.Zone)
+		}
+	})
+}
+
+func TestLoadNumericRanges(t *testing.T) {
+	type rangeConfig struct {
+		Port   int16   `configly:"PORT"`
+		Level  int8    `configly:"LEVEL"`
+		Byte   uint8   `configly:"BYTE"`
+		Count  uint64  `configly:"COUNT"`
+		Buffer int32   `configly:"BUFFER,bytes"`
+		Ratio  float32 `configly:"RATIO"`
+		Scale  float64 `configly:"SCALE"`
+		Offset int64   `configly:"OFFSET"`
+	}
+
+	t.Run("in range", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{
+			"PORT": "32767", "LEVEL": "-128", "BYTE": "0xFF", "COUNT": "18446744073709551615",
+			"BUFFER": "1GiB", "RATIO": "0.1", "SCALE": "1e308", "OFFSET": "-9223372036854775808",
+		})
+		l, _ := New[rangeConfig](LoaderConfig{Sources: []sources.Source{source}})
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if cfg.Port != 32767 || cfg.Level != -128 || cfg.Byte != 255 || cfg.Count != 18446744073709551615 || cfg.Buffer != 1<<30 {
+			t.Errorf("unexpected integer values: %+v", cfg)
+		}
+		if cfg.Ratio != float32(0.1) {
+			t.Errorf("expected ratio rounded to float32 0.1, got: %v", cfg.Ratio)
+		}
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{
+			"PORT": "70000", "LEVEL": "-129", "BYTE": "-1", "COUNT": "18446744073709551616",
+			"BUFFER": "2GiB", "RATIO": "1e39", "SCALE": "1e309", "OFFSET": "9223372036854775808",
+		})
+		l, _ := New[rangeConfig](LoaderConfig{Sources: []sources.Source{source}})
+		_, err := l.Load()
+		if err == nil {
+			t.Fatal("expected range errors")
+		}
+		for _, expected := range []string{
+			"value 70000 out of range for int16 (-32768 to 32767)",
+			"value -129 out of range for int8 (-128 to 127)",
+			"value -1 out of range for uint8 (0 to 255)",
+			"value 18446744073709551616 out of range for uint64 (0 to 18446744073709551615)",
+			"value 2GiB out of range for int32 (-2147483648 to 2147483647)",
+			"value 1e39 out of range for float32 (-3.4028234663852886e+38 to 3.4028234663852886e+38)",
+			"value 1e309 out of range for float64",
+			"value 9223372036854775808 out of range for int64 (-9223372036854775808 to 9223372036854775807)",
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %s", expected, err)
+			}
+		}
+	})
+
+	t.Run("float32 precision loss", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{"RATIO": "16777217"})
+		l, _ := New[rangeConfig](LoaderConfig{Sources: []sources.Source{source}})
+		_, err := l.Load()
+		if err == nil || !strings.Contains(err.Error(), "16777217 cannot be represented exactly as float32 (nearest is 16777216)") {
+			t.Errorf("expected precision error, got: %v", err)
+		}
+	})
+}
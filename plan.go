@@ -0,0 +1,89 @@
+package configly
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// fieldPlan is the compiled form of a configuration type's struct tags. It is
+// built once per type, tag key, Interpolate setting, and set of custom
+// validator names (see compilePlan), and shared by every Loader for that
+// combination, so Load does not repeat tag parsing and reflection over the
+// type.
+type fieldPlan struct {
+	fields    []tagOptions          // Tagged fields in declaration order, nested fields included
+	byKey     map[string]tagOptions // Tagged fields by configuration key
+	validated []validatedStruct     // Structs implementing Validate() error, nested structs first
+}
+
+// validatedStruct locates a struct, the configuration type itself or a
+// nested struct, whose Validate method is called after loading.
+type validatedStruct struct {
+	index []int  // Index path from the configuration type; empty for the type itself
+	path  string // Dotted Go path used to prefix errors; empty for the type itself
+}
+
+// planKey identifies a cached fieldPlan.
+type planKey struct {
+	typ         reflect.Type
+	tagKey      string
+	interpolate bool   // Whether defaults with references are checked when loading
+	validators  string // Sorted custom validator names, joined by commas
+}
+
+// planCache holds compiled plans by planKey. Plans are immutable once stored.
+var planCache sync.Map
+
+// compilePlan returns the field plan for T under the Loader's tag key,
+// Interpolate setting, and custom validators, parsing T's tags on first use.
+// Returns the joined tag parsing errors if T's tags are invalid; failed
+// plans are not cached.
+func (l *Loader[T]) compilePlan() (*fieldPlan, error) {
+	typ := reflect.TypeFor[T]()
+	key := planKey{
+		typ:         typ,
+		tagKey:      l.tagKey,
+		interpolate: l.interpolate,
+		validators:  strings.Join(slices.Sorted(maps.Keys(l.validators)), ","),
+	}
+	if cached, ok := planCache.Load(key); ok {
+		return cached.(*fieldPlan), nil
+	}
+
+	var cfg T
+	fields, err := l.parseAllTags(typ.NumField(), reflect.ValueOf(&cfg).Elem())
+	if err != nil {
+		return nil, err
+	}
+	plan := &fieldPlan{
+		fields:    fields,
+		byKey:     make(map[string]tagOptions, len(fields)),
+		validated: validatedStructs(typ, nil, ""),
+	}
+	for _, opts := range fields {
+		plan.byKey[opts.key] = opts
+	}
+
+	cached, _ := planCache.LoadOrStore(key, plan)
+	return cached.(*fieldPlan), nil
+}
+
+// validatedStructs lists typ and the exported nested structs within it whose
+// pointer implements Validate() error, nested structs before their parents.
+func validatedStructs(typ reflect.Type, index []int, path string) []validatedStruct {
+	var validated []validatedStruct
+	for idx := range typ.NumField() {
+		field := typ.Field(idx)
+		if !field.IsExported() || !isNestedStruct(field.Type) {
+			continue
+		}
+		validated = append(validated, validatedStructs(field.Type, append(slices.Clip(index), idx), path+field.Name+".")...)
+	}
+	if reflect.PointerTo(typ).Implements(validatorType) {
+		validated = append(validated, validatedStruct{index: index, path: strings.TrimSuffix(path, ".")})
+	}
+	return validated
+}
@@ -0,0 +1,51 @@
+package configly
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zanedma/configly/sources"
+)
+
+type planConfig struct {
+	Name  string `configly:"NAME,required" alt:"APP_NAME"`
+	Level int    `configly:"LEVEL,level" alt:"APP_LEVEL"`
+	TLS   struct {
+		Cert string `configly:"CERT"`
+	} `configly:"TLS"`
+}
+
+func TestCompilePlan(t *testing.T) {
+	level := func(any, string) error { return nil }
+	newLoader := func(t *testing.T, cfg LoaderConfig) *Loader[planConfig] {
+		t.Helper()
+		cfg.Sources = []sources.Source{sources.FromMap(nil)}
+		l, err := New[planConfig](cfg)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		return l
+	}
+
+	first := newLoader(t, LoaderConfig{Validators: map[string]ValidatorFunc{"level": level}})
+	second := newLoader(t, LoaderConfig{Validators: map[string]ValidatorFunc{"level": level}})
+	if first.plan != second.plan {
+		t.Error("expected loaders for the same type to share a plan")
+	}
+	if len(first.plan.fields) != 3 || first.plan.byKey["TLS_CERT"].fieldPath != "TLS.Cert" {
+		t.Errorf("unexpected plan fields: %+v", first.plan.fields)
+	}
+
+	alt := newLoader(t, LoaderConfig{TagKey: "alt", Validators: map[string]ValidatorFunc{"level": level}})
+	if alt.plan == first.plan {
+		t.Error("expected a separate plan for a different tag key")
+	}
+	if _, ok := alt.plan.byKey["APP_NAME"]; !ok {
+		t.Errorf("expected plan keyed by alt tags, got: %v", alt.plan.byKey)
+	}
+
+	_, err := New[planConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}})
+	if err == nil || !strings.Contains(err.Error(), `unknown tag option "level"`) {
+		t.Errorf("expected plan without the custom validator to fail, got: %v", err)
+	}
+}
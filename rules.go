@@ -0,0 +1,222 @@
+package configly
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// comparisonOps maps the field comparison tag options to the description
+// used in error messages.
+var comparisonOps = map[string]string{
+	"gtfield":  "greater than",
+	"gtefield": "greater than or equal to",
+	"ltfield":  "less than",
+	"ltefield": "less than or equal to",
+}
+
+// keyCondition is a required_if condition: the field is required when the
+// field loaded from key has value.
+type keyCondition struct {
+	key   string
+	value string
+}
+
+// fieldComparison is a gtfield, gtefield, ltfield, or ltefield rule comparing
+// a field with a sibling field of the same struct.
+type fieldComparison struct {
+	op         string // Tag option, e.g. gtfield
+	field      string // Go name of the sibling field
+	fieldIndex []int  // Index path of the sibling field, resolved by resolveRules
+}
+
+// validator is implemented by configuration types and nested structs that
+// check their own invariants after loading.
+type validator interface {
+	Validate() error
+}
+
+var validatorType = reflect.TypeOf((*validator)(nil)).Elem()
+
+// isComparisonOption reports whether a tag option part is a field comparison.
+func isComparisonOption(part string) bool {
+	op, _, found := strings.Cut(part, "=")
+	_, known := comparisonOps[op]
+	return found && known
+}
+
+// resolveRules checks that the keys referenced by required_if, required_with,
+// and excluded_with belong to tagged fields, that required_if values parse as
+// the referenced field's type, and resolves comparison rules to sibling
+// fields of a compatible numeric type.
+func (l *Loader[T]) resolveRules(typ reflect.Type, tagOpts []tagOptions) []error {
+	fields := make(map[string]tagOptions, len(tagOpts))
+	for _, opts := range tagOpts {
+		fields[opts.key] = opts
+	}
+
+	var ruleErrors []error
+	checkKey := func(opts tagOptions, option, key string) bool {
+		if _, ok := fields[key]; !ok {
+			ruleErrors = append(ruleErrors, fmt.Errorf("invalid %s rule on %s: unknown key %s", option, opts.key, key))
+			return false
+		}
+		return true
+	}
+
+	for i := range tagOpts {
+		opts := &tagOpts[i]
+		for _, cond := range opts.requiredIf {
+			if !checkKey(*opts, "required_if", cond.key) {
+				continue
+			}
+			if _, err := l.parseAs(typ.FieldByIndex(fields[cond.key].fieldIndex).Type, cond.value, fields[cond.key]); err != nil {
+				ruleErrors = append(ruleErrors, fmt.Errorf("invalid required_if rule on %s: %w", opts.key, err))
+			}
+		}
+		for _, key := range opts.requiredWith {
+			checkKey(*opts, "required_with", key)
+		}
+		for _, key := range opts.excludedWith {
+			checkKey(*opts, "excluded_with", key)
+		}
+
+		fieldType := typ.FieldByIndex(opts.fieldIndex).Type
+		parentIndex := opts.fieldIndex[:len(opts.fieldIndex)-1]
+		parentType := typ
+		if len(parentIndex) > 0 {
+			parentType = typ.FieldByIndex(parentIndex).Type
+		}
+		for j := range opts.comparisons {
+			rule := &opts.comparisons[j]
+			sibling, ok := parentType.FieldByName(rule.field)
+			if !ok {
+				ruleErrors = append(ruleErrors, fmt.Errorf("invalid %s rule on %s: unknown field %s", rule.op, opts.key, rule.field))
+				continue
+			}
+			if numericKind(fieldType.Kind()) == "" || numericKind(fieldType.Kind()) != numericKind(sibling.Type.Kind()) {
+				ruleErrors = append(ruleErrors, fmt.Errorf("invalid %s rule on %s: cannot compare %s with %s", rule.op, opts.key, fieldType, sibling.Type))
+				continue
+			}
+			rule.fieldIndex = append(slices.Clone(parentIndex), sibling.Index...)
+		}
+	}
+	return ruleErrors
+}
+
+// parseAs parses str into a new value of typ using setField with the options
+// of the field it is compared with.
+func (l *Loader[T]) parseAs(typ reflect.Type, str string, opts tagOptions) (reflect.Value, error) {
+	value := reflect.New(typ).Elem()
+	if err := l.setField(&value, str, opts); err != nil {
+		return reflect.Value{}, err
+	}
+	return value, nil
+}
+
+// numericKind groups numeric kinds into the families that can be compared
+// with each other. Returns "" for non-numeric kinds.
+func numericKind(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	}
+	return ""
+}
+
+// checkFieldRules applies the cross-field tag rules. A field or referenced
+// key has a value when it appears in provenance, i.e. it was loaded from a
+// source or a tag default. Comparisons are only checked for fields that have
+// a value.
+func (l *Loader[T]) checkFieldRules(val reflect.Value, provenance Provenance) []error {
+	fields := l.plan.byKey
+	hasValue := func(key string) bool {
+		_, ok := provenance[key]
+		return ok
+	}
+
+	var ruleErrors []error
+	for _, opts := range l.plan.fields {
+		present := hasValue(opts.key)
+
+		for _, cond := range opts.requiredIf {
+			if present || !hasValue(cond.key) {
+				continue
+			}
+			expected, _ := l.parseAs(val.FieldByIndex(fields[cond.key].fieldIndex).Type(), cond.value, fields[cond.key])
+			if reflect.DeepEqual(val.FieldByIndex(fields[cond.key].fieldIndex).Interface(), expected.Interface()) {
+				ruleErrors = append(ruleErrors, fmt.Errorf("%s is required when %s is %s", opts.key, cond.key, cond.value))
+			}
+		}
+		if !present {
+			for _, key := range opts.requiredWith {
+				if hasValue(key) {
+					ruleErrors = append(ruleErrors, fmt.Errorf("%s is required when %s is set", opts.key, key))
+					break
+				}
+			}
+			continue
+		}
+		for _, key := range opts.excludedWith {
+			if hasValue(key) {
+				ruleErrors = append(ruleErrors, fmt.Errorf("%s must not be set when %s is set", opts.key, key))
+			}
+		}
+
+		field := val.FieldByIndex(opts.fieldIndex)
+		for _, rule := range opts.comparisons {
+			other := val.FieldByIndex(rule.fieldIndex)
+			if !compareFields(rule.op, field, other) {
+				ruleErrors = append(ruleErrors, fmt.Errorf("%s: value %v must be %s %s (%v)", opts.key, field.Interface(), comparisonOps[rule.op], rule.field, other.Interface()))
+			}
+		}
+	}
+	return ruleErrors
+}
+
+// compareFields reports whether field op other holds for two values of the
+// same numeric family.
+func compareFields(op string, field, other reflect.Value) bool {
+	var c int
+	switch numericKind(field.Kind()) {
+	case "int":
+		c = cmp.Compare(field.Int(), other.Int())
+	case "uint":
+		c = cmp.Compare(field.Uint(), other.Uint())
+	case "float":
+		c = cmp.Compare(field.Float(), other.Float())
+	}
+	switch op {
+	case "gtfield":
+		return c > 0
+	case "gtefield":
+		return c >= 0
+	case "ltfield":
+		return c < 0
+	default: // ltefield
+		return c <= 0
+	}
+}
+
+// validateStructs calls Validate on each struct of val listed in validated
+// (see validatedStructs), nested structs before val itself. Errors from
+// nested structs are prefixed with the struct's field path.
+func validateStructs(val reflect.Value, validated []validatedStruct) []error {
+	var validateErrors []error
+	for _, target := range validated {
+		v := val.FieldByIndex(target.index).Addr().Interface().(validator)
+		if err := v.Validate(); err != nil {
+			if target.path != "" {
+				err = fmt.Errorf("%s: %w", target.path, err)
+			}
+			validateErrors = append(validateErrors, err)
+		}
+	}
+	return validateErrors
+}
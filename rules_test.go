@@ -0,0 +1,199 @@
+package configly
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zanedma/configly/sources"
+)
+
+type tlsConfig struct {
+	Enabled bool   `configly:"ENABLED"`
+	Cert    string `configly:"CERT,required_if=TLS_ENABLED:true"`
+	Key     string `configly:"KEY,required_with=TLS_CERT"`
+}
+
+func (c tlsConfig) Validate() error {
+	if c.Cert != "" && !strings.HasSuffix(c.Cert, ".pem") {
+		return errors.New("cert must be a .pem file")
+	}
+	return nil
+}
+
+type poolConfig struct {
+	MinConns int           `configly:"MIN_CONNS,default=1"`
+	MaxConns int           `configly:"MAX_CONNS,gtefield=MinConns"`
+	Idle     time.Duration `configly:"IDLE,default=1m"`
+	MaxIdle  time.Duration `configly:"MAX_IDLE,gtfield=Idle"`
+}
+
+type rulesConfig struct {
+	Host   string    `configly:"HOST,default=localhost"`
+	Socket string    `configly:"SOCKET,excluded_with=HOST"`
+	TLS    tlsConfig `configly:"TLS"`
+	Pool   poolConfig
+}
+
+func (c *rulesConfig) Validate() error {
+	if c.Host == "forbidden" {
+		return errors.New("host is forbidden")
+	}
+	return nil
+}
+
+func loadRulesConfig(t *testing.T, values map[string]string) (*rulesConfig, error) {
+	t.Helper()
+	l, err := New[rulesConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(values)}})
+	if err != nil {
+		t.Fatalf("failed to create loader: %s", err)
+	}
+	return l.Load()
+}
+
+func TestNestedStructs(t *testing.T) {
+	cfg, err := loadRulesConfig(t, map[string]string{
+		"TLS_ENABLED": "true",
+		"TLS_CERT":    "server.pem",
+		"TLS_KEY":     "server.key",
+		"MAX_CONNS":   "10",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %s", err)
+	}
+	if !cfg.TLS.Enabled || cfg.TLS.Cert != "server.pem" || cfg.TLS.Key != "server.key" {
+		t.Errorf("expected TLS fields to be loaded from TLS_ keys, got: %+v", cfg.TLS)
+	}
+	if cfg.Pool.MinConns != 1 || cfg.Pool.MaxConns != 10 {
+		t.Errorf("expected untagged nested struct to use unprefixed keys, got: %+v", cfg.Pool)
+	}
+
+	t.Run("error for options on nested struct tag", func(t *testing.T) {
+		type invalidConfig struct {
+			TLS tlsConfig `configly:"TLS,required"`
+		}
+		if _, err := New[invalidConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}}); err == nil {
+			t.Error("expected error for options on nested struct tag")
+		}
+	})
+}
+
+func TestCrossFieldRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   map[string]string
+		expected []string
+	}{
+		{
+			name:     "required_if",
+			values:   map[string]string{"TLS_ENABLED": "1"},
+			expected: []string{"TLS_CERT is required when TLS_ENABLED is true"},
+		},
+		{
+			name:     "required_with",
+			values:   map[string]string{"TLS_CERT": "server.pem"},
+			expected: []string{"TLS_KEY is required when TLS_CERT is set"},
+		},
+		{
+			name:     "excluded_with",
+			values:   map[string]string{"SOCKET": "/run/app.sock"},
+			expected: []string{"SOCKET must not be set when HOST is set"},
+		},
+		{
+			name:     "gtefield and gtfield",
+			values:   map[string]string{"MIN_CONNS": "5", "MAX_CONNS": "2", "MAX_IDLE": "30s"},
+			expected: []string{"MAX_CONNS: value 2 must be greater than or equal to MinConns (5)", "MAX_IDLE: value 30s must be greater than Idle (1m0s)"},
+		},
+		{
+			name:     "Validate hooks merged with field errors",
+			values:   map[string]string{"HOST": "forbidden", "TLS_CERT": "server.crt", "TLS_KEY": "k", "MAX_CONNS": "x"},
+			expected: []string{"error setting MAX_CONNS", "TLS: cert must be a .pem file", "host is forbidden"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadRulesConfig(t, tt.values)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error to contain %q, got: %s", expected, err)
+				}
+			}
+		})
+	}
+
+	t.Run("rules pass", func(t *testing.T) {
+		_, err := loadRulesConfig(t, map[string]string{"TLS_ENABLED": "false", "MAX_CONNS": "1", "MAX_IDLE": "2m"})
+		if err != nil {
+			t.Errorf("expected no error, got: %s", err)
+		}
+	})
+}
+
+func TestInvalidRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		load     func() error
+		expected string
+	}{
+		{
+			name: "unknown key",
+			load: func() error {
+				type cfg struct {
+					A string `configly:"A,required_with=MISSING"`
+				}
+				_, err := New[cfg](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}})
+				return err
+			},
+			expected: "invalid required_with rule on A: unknown key MISSING",
+		},
+		{
+			name: "unparseable required_if value",
+			load: func() error {
+				type cfg struct {
+					On bool   `configly:"ON"`
+					A  string `configly:"A,required_if=ON:maybe"`
+				}
+				_, err := New[cfg](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}})
+				return err
+			},
+			expected: "invalid required_if rule on A: invalid boolean",
+		},
+		{
+			name: "unknown field",
+			load: func() error {
+				type cfg struct {
+					A int `configly:"A,gtfield=B"`
+				}
+				_, err := New[cfg](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}})
+				return err
+			},
+			expected: "invalid gtfield rule on A: unknown field B",
+		},
+		{
+			name: "incompatible field",
+			load: func() error {
+				type cfg struct {
+					A int    `configly:"A,ltfield=B"`
+					B string `configly:"B"`
+				}
+				_, err := New[cfg](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}})
+				return err
+			},
+			expected: "invalid ltfield rule on A: cannot compare int with string",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.load()
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error containing %q, got: %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,550 @@
+package configly
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/joho/godotenv"
+	"github.com/zanedma/configly/sources"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// jsonSchemaDraft is the JSON Schema dialect emitted by JSONSchema.
+	jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+	// durationPattern matches strings accepted by parseDuration.
+	durationPattern = `^[-+]?(0|(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h|d|w))+)$`
+	// integerPattern matches strings accepted by parseInteger.
+	integerPattern = `^[-+]?(0[xX](_?[0-9a-fA-F])+|0[oO](_?[0-7])+|0[bB](_?[01])+|[0-9](_?[0-9])*)$`
+	// byteSizePattern matches strings accepted by ParseByteSize. JSON Schema
+	// patterns have no case-insensitive flag, so units use character classes.
+	byteSizePattern = `^\s*\+?(0[xX](_?[0-9a-fA-F])+|0[oO](_?[0-7])+|0[bB](_?[01])+|[0-9](_?[0-9])*|(([0-9](_?[0-9])*(\.[0-9]*)?|\.[0-9]+)\s*([bB]|[kKmMgGtTpPeE]([iI]?[bB])?)))\s*$`
+)
+
+// jsonSchema is the root object of a generated JSON Schema document.
+type jsonSchema struct {
+	Schema               string                     `json:"$schema"`
+	Title                string                     `json:"title,omitempty"`
+	Type                 string                     `json:"type"`
+	Properties           map[string]*schemaProperty `json:"properties"`
+	Required             []string                   `json:"required,omitempty"`
+	AllOf                []*schemaRequirement       `json:"allOf,omitempty"`
+	AdditionalProperties bool                       `json:"additionalProperties"`
+}
+
+// schemaProperty describes a single configuration key in a JSON Schema
+// document, or a nested object holding the keys of a nested struct.
+type schemaProperty struct {
+	Type                 string                     `json:"type,omitempty"`
+	Format               string                     `json:"format,omitempty"`
+	Pattern              string                     `json:"pattern,omitempty"`
+	Default              any                        `json:"default,omitempty"`
+	Enum                 []any                      `json:"enum,omitempty"`
+	Minimum              *int64                     `json:"minimum,omitempty"`
+	Maximum              *int64                     `json:"maximum,omitempty"`
+	MinLength            *int                       `json:"minLength,omitempty"`
+	MaxLength            *int                       `json:"maxLength,omitempty"`
+	Properties           map[string]*schemaProperty `json:"properties,omitempty"`
+	AdditionalProperties *bool                      `json:"additionalProperties,omitempty"`
+	OneOf                []*schemaProperty          `json:"oneOf,omitempty"`
+}
+
+// schemaRequirement requires a key of a nested struct, which a document may
+// set either flattened (TLS_CERT) or through nested objects (TLS: {CERT: ...}).
+type schemaRequirement struct {
+	Required   []string                      `json:"required,omitempty"`
+	Properties map[string]*schemaRequirement `json:"properties,omitempty"`
+	AnyOf      []*schemaRequirement          `json:"anyOf,omitempty"`
+}
+
+// schemaField pairs a field's parsed tag options with the field's type.
+type schemaField struct {
+	opts tagOptions
+	typ  reflect.Type
+}
+
+// documentEntry is a single key read from a configuration document, along
+// with its raw decoded value and the line it was declared on. Keys of nested
+// objects are flattened as sources do, e.g. DB: {HOST: ...} is read as
+// DB_HOST with path DB.HOST.
+type documentEntry struct {
+	key      string
+	path     string // Dotted path of the key in the document
+	document int    // Index of the document in a multi-document YAML file
+	value    any
+	line     int
+}
+
+// JSONSchema generates a JSON Schema (draft 2020-12) document describing the
+// configuration keys of T. Each tagged field becomes a property keyed by its
+// configuration key, with its type, default, and min/max/minLen/maxLen,
+// pattern, and oneof (as enum) constraints. Fields of nested structs are
+// described both by their flattened keys (TLS_CERT) and as nested objects
+// (TLS: {CERT: ...}), since sources flatten nested objects. Required fields
+// are listed under "required", in either form for nested structs, and
+// unknown keys are disallowed.
+func (l *Loader[T]) JSONSchema() ([]byte, error) {
+	fields := l.schemaFields()
+
+	typ := reflect.TypeFor[T]()
+	schema := jsonSchema{
+		Schema:     jsonSchemaDraft,
+		Title:      typ.Name(),
+		Type:       "object",
+		Properties: make(map[string]*schemaProperty, len(fields)),
+	}
+	for _, field := range fields {
+		schema.Properties[field.opts.key] = l.schemaProperty(field)
+	}
+	l.addNestedSchema(&schema, typ, "", nil, schema.Properties)
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// addNestedSchema walks the fields of typ, whose keys start with keyPrefix,
+// adding an object property to props for each tagged nested struct and the
+// requirements of required fields to schema. chain holds the tags of the
+// nested structs enclosing typ. Nested objects reuse the properties of the
+// flattened keys in schema.Properties.
+func (l *Loader[T]) addNestedSchema(schema *jsonSchema, typ reflect.Type, keyPrefix string, chain []string, props map[string]*schemaProperty) {
+	for idx := range typ.NumField() {
+		field := typ.Field(idx)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get(l.tagKey), ",")
+		if !isNestedStruct(field.Type) {
+			opts, ok := l.plan.byKey[keyPrefix+name]
+			if name == "" || !ok || !opts.required {
+				continue
+			}
+			if len(chain) == 0 {
+				schema.Required = append(schema.Required, name)
+			} else {
+				schema.AllOf = append(schema.AllOf, nestedRequirement(chain, name))
+			}
+			continue
+		}
+		if name == "" {
+			l.addNestedSchema(schema, field.Type, keyPrefix, chain, props)
+			continue
+		}
+
+		prefix := keyPrefix + name + nestedKeySeparator
+		object := &schemaProperty{
+			Type:                 "object",
+			Properties:           make(map[string]*schemaProperty),
+			AdditionalProperties: new(bool),
+		}
+		for key, prop := range schema.Properties {
+			if rel, ok := strings.CutPrefix(key, prefix); ok {
+				object.Properties[rel] = prop
+			}
+		}
+		l.addNestedSchema(schema, field.Type, prefix, append(slices.Clip(chain), name), object.Properties)
+		props[name] = object
+	}
+}
+
+// nestedRequirement requires key of the nested struct reached through the
+// struct tags in chain, set either flattened at this level or inside the
+// object named by the first tag.
+func nestedRequirement(chain []string, key string) *schemaRequirement {
+	if len(chain) == 0 {
+		return &schemaRequirement{Required: []string{key}}
+	}
+	flat := strings.Join(chain, nestedKeySeparator) + nestedKeySeparator + key
+	return &schemaRequirement{AnyOf: []*schemaRequirement{
+		{Required: []string{flat}},
+		{
+			Required:   []string{chain[0]},
+			Properties: map[string]*schemaRequirement{chain[0]: nestedRequirement(chain[1:], key)},
+		},
+	}}
+}
+
+// ValidateFile checks a configuration file against the schema of T.
+// The file format is detected the same way sources.FromFile detects it, and
+// nested objects are flattened into keys the same way.
+// Keys that do not correspond to a field of T, values that cannot be converted
+// to the field's type, and values that violate the field's constraints are
+// reported with the line they appear on. Encrypted ENC[...] values are only
+// checked for unknown keys, since their plaintext is not known until a
+// source decrypts them. Required keys missing from the file are not reported,
+// since a file is usually only one of several sources.
+// Returns nil if the file is valid, or all problems joined together.
+func (l *Loader[T]) ValidateFile(path string) error {
+	fields := l.schemaFields()
+
+	entries, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	var validationErrors []error
+	type documentKey struct {
+		document int
+		key      string
+	}
+	seen := make(map[documentKey]documentEntry)
+	for _, entry := range entries {
+		if other, ok := seen[documentKey{entry.document, entry.key}]; ok {
+			validationErrors = append(validationErrors, fmt.Errorf("%s:%d: key %s is set by both %s (line %d) and %s", path, entry.line, entry.key, other.path, other.line, entry.path))
+			continue
+		}
+		seen[documentKey{entry.document, entry.key}] = entry
+
+		field, ok := fields[entry.key]
+		if !ok {
+			validationErrors = append(validationErrors, fmt.Errorf("%s:%d: unknown key %s", path, entry.line, entry.key))
+			continue
+		}
+		strVal, ok := sources.ScalarString(entry.value)
+		if !ok {
+			validationErrors = append(validationErrors, fmt.Errorf("%s:%d: %s: expected %s, got %s", path, entry.line, entry.key, schemaType(field.typ), documentType(entry.value)))
+			continue
+		}
+		if sources.IsEncrypted(strVal) {
+			continue
+		}
+		fieldValue := reflect.New(field.typ).Elem()
+		if err := l.setField(&fieldValue, strVal, field.opts); err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("%s:%d: %s: %w", path, entry.line, entry.key, err))
+			continue
+		}
+		if err := l.validateField(fieldValue, field.opts); err != nil {
+			validationErrors = append(validationErrors, fmt.Errorf("%s:%d: %s: %w", path, entry.line, entry.key, err))
+		}
+	}
+
+	return errors.Join(validationErrors...)
+}
+
+// schemaFields returns the tagged fields of T keyed by their configuration
+// key.
+func (l *Loader[T]) schemaFields() map[string]schemaField {
+	typ := reflect.TypeFor[T]()
+	fields := make(map[string]schemaField, len(l.plan.fields))
+	for _, opts := range l.plan.fields {
+		fields[opts.key] = schemaField{
+			opts: opts,
+			typ:  typ.FieldByIndex(opts.fieldIndex).Type,
+		}
+	}
+	return fields
+}
+
+// schemaProperty builds the JSON Schema property for a single field.
+// The tag default is converted to the field's type so that it is emitted as
+// a JSON number or boolean where appropriate; defaults that cannot be
+// converted are emitted as strings.
+func (l *Loader[T]) schemaProperty(field schemaField) *schemaProperty {
+	prop := &schemaProperty{
+		Type:      schemaType(field.typ),
+		MinLength: field.opts.minLen,
+		MaxLength: field.opts.maxLen,
+	}
+	if field.typ == durationType {
+		// The "duration" format means ISO 8601 durations, which parseDuration
+		// does not accept, so only the pattern is emitted.
+		prop.Pattern = durationPattern
+	} else if field.typ == timeType && timeLayout(field.opts) == time.RFC3339 {
+		prop.Format = "date-time"
+	} else if field.opts.bytes || field.typ == byteSizeType {
+		prop.Type = "integer"
+		prop.Minimum = field.opts.min
+		prop.Maximum = field.opts.max
+	} else if prop.Type == "integer" || prop.Type == "number" {
+		prop.Minimum = field.opts.min
+		prop.Maximum = field.opts.max
+	}
+	if kind := field.typ.Kind(); kind != reflect.Slice && kind != reflect.Map {
+		// Slices and maps are written as a single string, so the element
+		// constraints do not apply to the property as a whole.
+		if field.opts.pattern != nil {
+			prop.Pattern = field.opts.pattern.String()
+		}
+		for _, value := range field.opts.oneOf {
+			if val, err := parseInteger(value, 64); err == nil && prop.Type == "integer" {
+				prop.Enum = append(prop.Enum, val)
+			} else {
+				prop.Enum = append(prop.Enum, value)
+			}
+		}
+	}
+
+	if field.opts.hasDefault {
+		prop.Default = field.opts.defaultValue
+		defaultValue := reflect.New(field.typ).Elem()
+		if prop.Type == "integer" && (field.opts.bytes || field.typ == byteSizeType) {
+			// Keep byte size defaults as written, e.g. "64MiB".
+		} else if prop.Type != "string" && l.setField(&defaultValue, field.opts.defaultValue, field.opts) == nil {
+			prop.Default = defaultValue.Interface()
+		}
+	}
+
+	// Integers may also be written as strings, e.g. "0x1F", "1_000", or
+	// "64MiB" for byte sizes.
+	if field.opts.bytes || field.typ == byteSizeType {
+		withStringForm(prop, byteSizePattern)
+	} else if prop.Type == "integer" {
+		withStringForm(prop, integerPattern)
+	} else if prop.Type == "boolean" {
+		// Booleans may also be written as e.g. "yes" or "off".
+		withStringForm(prop, booleanPattern())
+	}
+	return prop
+}
+
+// booleanPattern matches strings accepted by parseBool. JSON Schema patterns
+// have no case-insensitive flag, so each letter is a character class.
+func booleanPattern() string {
+	var pattern strings.Builder
+	pattern.WriteString("^(")
+	for i, value := range slices.Concat(trueValues, falseValues) {
+		if i > 0 {
+			pattern.WriteString("|")
+		}
+		for _, r := range value {
+			if upper := unicode.ToUpper(r); upper != r {
+				pattern.WriteString("[" + string(r) + string(upper) + "]")
+			} else {
+				pattern.WriteRune(r)
+			}
+		}
+	}
+	pattern.WriteString(")$")
+	return pattern.String()
+}
+
+// withStringForm lets prop be written either as its JSON type or as a string
+// matching pattern. The type and its constraints move into the first
+// alternative of a oneOf.
+func withStringForm(prop *schemaProperty, pattern string) {
+	prop.OneOf = []*schemaProperty{
+		{Type: prop.Type, Enum: prop.Enum, Minimum: prop.Minimum, Maximum: prop.Maximum},
+		{Type: "string", Pattern: pattern},
+	}
+	prop.Type, prop.Enum, prop.Minimum, prop.Maximum = "", nil, nil, nil
+}
+
+// schemaType returns the JSON Schema type name for a Go field type.
+// time.Duration and ByteSize are represented as strings since they are
+// usually written as e.g. "30s" and "64MiB".
+func schemaType(typ reflect.Type) string {
+	if typ == durationType || typ == byteSizeType {
+		return "string"
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	default:
+		return "string"
+	}
+}
+
+// documentType returns the JSON Schema type name of a decoded document value.
+func documentType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// readDocument reads the keys of a configuration file, with nested objects
+// flattened, together with their raw values and line numbers.
+func readDocument(path string) ([]documentEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	format, err := sources.DetectFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case sources.FormatJSON:
+		return readJSONDocument(data)
+	case sources.FormatJSONC, sources.FormatJSON5:
+		normalized, err := sources.NormalizeJSON5(data)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s file: %w", format, err)
+		}
+		return readJSONDocument(normalized)
+	case sources.FormatYAML:
+		return readYAMLDocument(data)
+	default:
+		return readEnvDocument(data)
+	}
+}
+
+// readJSONDocument streams the top-level object of a JSON document, recording
+// the line each key appears on.
+func readJSONDocument(data []byte) ([]documentEntry, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil {
+		return nil, fmt.Errorf("error parsing json file: %w", err)
+	} else if tok != json.Delim('{') {
+		return nil, errors.New("error parsing json file: top-level value must be an object")
+	}
+	return readJSONObject(dec, data, 0, nil)
+}
+
+// readJSONObject reads the members of the object dec is positioned in,
+// descending into nested objects. dec reads data from offset base, and
+// parent is the entry of the object itself, or nil for the top level.
+func readJSONObject(dec *json.Decoder, data []byte, base int64, parent *documentEntry) ([]documentEntry, error) {
+	var entries []documentEntry
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("error parsing json file: %w", err)
+		}
+		entry := nestedEntry(parent, tok.(string), lineAt(data, base+dec.InputOffset()))
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("error parsing json file (line %d): %w", entry.line, err)
+		}
+		if !bytes.HasPrefix(raw, []byte("{")) {
+			if err := json.Unmarshal(raw, &entry.value); err != nil {
+				return nil, fmt.Errorf("error parsing json file (line %d): %w", entry.line, err)
+			}
+			entries = append(entries, entry)
+			continue
+		}
+
+		objectStart := base + dec.InputOffset() - int64(len(raw))
+		nested := json.NewDecoder(bytes.NewReader(raw))
+		if _, err := nested.Token(); err != nil {
+			return nil, fmt.Errorf("error parsing json file (line %d): %w", entry.line, err)
+		}
+		nestedEntries, err := readJSONObject(nested, data, objectStart, &entry)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, nestedEntries...)
+	}
+	return entries, nil
+}
+
+// nestedEntry returns the entry for key inside the object of parent, or at
+// the top level if parent is nil, flattening its key as sources do.
+func nestedEntry(parent *documentEntry, key string, line int) documentEntry {
+	if parent == nil {
+		return documentEntry{key: key, path: key, line: line}
+	}
+	return documentEntry{
+		key:      parent.key + nestedKeySeparator + key,
+		path:     parent.path + "." + key,
+		document: parent.document,
+		line:     line,
+	}
+}
+
+// readYAMLDocument walks the top-level mapping of every document in a YAML
+// stream, recording the line each key appears on.
+func readYAMLDocument(data []byte) ([]documentEntry, error) {
+	var entries []documentEntry
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for index := 0; ; index++ {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); errors.Is(err, io.EOF) {
+			return entries, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("error parsing yaml file: %w", err)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		root := doc.Content[0]
+		if root.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("error parsing yaml file (line %d): top-level value must be a mapping", root.Line)
+		}
+
+		mappingEntries, err := readYAMLMapping(root, &documentEntry{document: index})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, mappingEntries...)
+	}
+}
+
+// readYAMLMapping reads the keys of a mapping node, descending into nested
+// mappings. parent is the entry of the mapping itself; its key is empty for
+// the top level.
+func readYAMLMapping(mapping *yaml.Node, parent *documentEntry) ([]documentEntry, error) {
+	var entries []documentEntry
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		keyNode, valueNode := mapping.Content[i], mapping.Content[i+1]
+		entry := documentEntry{key: keyNode.Value, path: keyNode.Value, document: parent.document, line: keyNode.Line}
+		if parent.key != "" {
+			entry = nestedEntry(parent, keyNode.Value, keyNode.Line)
+		}
+		if valueNode.Kind == yaml.MappingNode {
+			nestedEntries, err := readYAMLMapping(valueNode, &entry)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, nestedEntries...)
+			continue
+		}
+		if err := valueNode.Decode(&entry.value); err != nil {
+			return nil, fmt.Errorf("error parsing yaml file (line %d): %w", valueNode.Line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// readEnvDocument parses a dotenv document and recovers the line each key is
+// first declared on, since godotenv does not report positions.
+func readEnvDocument(data []byte) ([]documentEntry, error) {
+	values, err := godotenv.UnmarshalBytes(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing env file: %w", err)
+	}
+
+	var entries []documentEntry
+	for idx, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "export ")
+		key, _, found := strings.Cut(line, "=")
+		if !found {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		entries = append(entries, documentEntry{key: key, path: key, value: value, line: idx + 1})
+		// only report the first declaration of each key
+		delete(values, key)
+	}
+	return entries, nil
+}
+
+// lineAt returns the 1-based line number of the given byte offset in data.
+func lineAt(data []byte, offset int64) int {
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
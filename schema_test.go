@@ -0,0 +1,409 @@
+package configly
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zanedma/configly/sources"
+)
+
+type schemaConfig struct {
+	Host    string        `configly:"HOST,required,minLen=1,maxLen=255"`
+	Port    int           `configly:"PORT,default=8080,min=1,max=65535"`
+	Ratio   float64       `configly:"RATIO,default=0.5"`
+	Debug   bool          `configly:"DEBUG,default=true"`
+	Timeout time.Duration `configly:"TIMEOUT,default=30s"`
+	Level   string        `configly:"LEVEL,default=info,oneof=debug info warn"`
+	Region  string        `configly:"REGION,pattern=^[a-z]+-[a-z]+-[0-9]$"`
+	Workers int           `configly:"WORKERS,oneof=1 2 4"`
+	Buffer  int64         `configly:"BUFFER,bytes,default=64MiB,min=1KiB,max=1GiB"`
+}
+
+type nestedSchemaConfig struct {
+	Name string `configly:"NAME,required"`
+	DB   struct {
+		Host string `configly:"HOST,required"`
+		Port int    `configly:"PORT,default=5432"`
+		TLS  struct {
+			CA string `configly:"CA"`
+		} `configly:"TLS"`
+	} `configly:"DB"`
+}
+
+// schemaAlternatives returns the integer and string alternatives of a
+// property that accepts either.
+func schemaAlternatives(t *testing.T, prop any) (map[string]any, map[string]any) {
+	t.Helper()
+	oneOf, _ := prop.(map[string]any)["oneOf"].([]any)
+	if len(oneOf) != 2 {
+		t.Fatalf("expected two oneOf alternatives, got: %v", prop)
+	}
+	return oneOf[0].(map[string]any), oneOf[1].(map[string]any)
+}
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %s", err)
+	}
+	return path
+}
+
+func TestJSONSchema(t *testing.T) {
+	l, _ := New[schemaConfig](LoaderConfig{Sources: []sources.Source{&sources.MockSource{SourceName: "test"}}})
+
+	out, err := l.JSONSchema()
+	if err != nil {
+		t.Fatalf("expected err to be nil, got: %s", err)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(out, &schema); err != nil {
+		t.Fatalf("expected valid JSON, got: %s", err)
+	}
+
+	t.Run("top level", func(t *testing.T) {
+		if schema["$schema"] != jsonSchemaDraft {
+			t.Errorf("expected $schema to be '%s', got: %v", jsonSchemaDraft, schema["$schema"])
+		}
+		if schema["type"] != "object" {
+			t.Errorf("expected type to be 'object', got: %v", schema["type"])
+		}
+		if schema["additionalProperties"] != false {
+			t.Errorf("expected additionalProperties to be false, got: %v", schema["additionalProperties"])
+		}
+		required, _ := schema["required"].([]any)
+		if len(required) != 1 || required[0] != "HOST" {
+			t.Errorf("expected required to be [HOST], got: %v", schema["required"])
+		}
+	})
+
+	props := schema["properties"].(map[string]any)
+
+	t.Run("string constraints", func(t *testing.T) {
+		host := props["HOST"].(map[string]any)
+		if host["type"] != "string" {
+			t.Errorf("expected HOST type to be 'string', got: %v", host["type"])
+		}
+		if host["minLength"] != float64(1) || host["maxLength"] != float64(255) {
+			t.Errorf("expected HOST length bounds 1..255, got: %v..%v", host["minLength"], host["maxLength"])
+		}
+	})
+
+	t.Run("numeric constraints and typed defaults", func(t *testing.T) {
+		port := props["PORT"].(map[string]any)
+		integer, str := schemaAlternatives(t, port)
+		if integer["type"] != "integer" {
+			t.Errorf("expected PORT type to be 'integer', got: %v", integer["type"])
+		}
+		if integer["minimum"] != float64(1) || integer["maximum"] != float64(65535) {
+			t.Errorf("expected PORT bounds 1..65535, got: %v..%v", integer["minimum"], integer["maximum"])
+		}
+		if str["type"] != "string" || str["pattern"] != integerPattern {
+			t.Errorf("expected PORT to accept integer strings, got: %v", str)
+		}
+		if port["default"] != float64(8080) {
+			t.Errorf("expected PORT default to be 8080, got: %v", port["default"])
+		}
+		ratio := props["RATIO"].(map[string]any)
+		if ratio["type"] != "number" || ratio["default"] != 0.5 {
+			t.Errorf("expected RATIO to be number with default 0.5, got: %v", ratio)
+		}
+		debug := props["DEBUG"].(map[string]any)
+		if boolean, _ := schemaAlternatives(t, debug); boolean["type"] != "boolean" || debug["default"] != true {
+			t.Errorf("expected DEBUG to be boolean with default true, got: %v", debug)
+		}
+	})
+
+	t.Run("duration", func(t *testing.T) {
+		timeout := props["TIMEOUT"].(map[string]any)
+		if timeout["type"] != "string" || timeout["format"] != nil {
+			t.Errorf("expected TIMEOUT to be string without format, got: %v", timeout)
+		}
+		pattern := regexp.MustCompile(timeout["pattern"].(string))
+		for _, value := range []string{"0", "-0", "30s", "1h30m", "1.5h", ".5s", "2d", "1w2d"} {
+			if _, err := parseDuration(value); err != nil {
+				t.Fatalf("expected %q to be a valid duration, got: %s", value, err)
+			}
+			if !pattern.MatchString(value) {
+				t.Errorf("expected TIMEOUT pattern to match %q", value)
+			}
+		}
+		for _, value := range []string{"", "30", "s", "1x", "P1D"} {
+			if pattern.MatchString(value) {
+				t.Errorf("expected TIMEOUT pattern not to match %q", value)
+			}
+		}
+		if timeout["default"] != "30s" {
+			t.Errorf("expected TIMEOUT default to be '30s', got: %v", timeout["default"])
+		}
+	})
+
+	t.Run("integer strings", func(t *testing.T) {
+		pattern := regexp.MustCompile(integerPattern)
+		for _, value := range []string{"0", "-12", "+7", "1_000", "0x1F", "0o755", "0b1010", "0x_1F"} {
+			if _, err := parseInteger(value, 64); err != nil {
+				t.Fatalf("expected %q to be a valid integer, got: %s", value, err)
+			}
+			if !pattern.MatchString(value) {
+				t.Errorf("expected integer pattern to match %q", value)
+			}
+		}
+		for _, value := range []string{"", "1.5", "_1", "1_", "1__0", "0x", "abc"} {
+			if pattern.MatchString(value) {
+				t.Errorf("expected integer pattern not to match %q", value)
+			}
+		}
+	})
+
+	t.Run("boolean strings", func(t *testing.T) {
+		_, str := schemaAlternatives(t, props["DEBUG"])
+		pattern := regexp.MustCompile(str["pattern"].(string))
+		for _, value := range []string{"true", "FALSE", "Yes", "no", "on", "OFF", "enabled", "Disabled", "1", "0", "t", "N"} {
+			if _, err := parseBool(value); err != nil {
+				t.Fatalf("expected %q to be a valid boolean, got: %s", value, err)
+			}
+			if !pattern.MatchString(value) {
+				t.Errorf("expected DEBUG pattern to match %q", value)
+			}
+		}
+		for _, value := range []string{"", "maybe", "truee", "2", "yes no"} {
+			if pattern.MatchString(value) {
+				t.Errorf("expected DEBUG pattern not to match %q", value)
+			}
+		}
+	})
+
+	t.Run("byte sizes", func(t *testing.T) {
+		buffer := props["BUFFER"].(map[string]any)
+		integer, str := schemaAlternatives(t, buffer)
+		if integer["type"] != "integer" || integer["minimum"] != float64(1024) || integer["maximum"] != float64(1<<30) {
+			t.Errorf("expected BUFFER to be integer with bounds 1024..1073741824, got: %v", integer)
+		}
+		if buffer["default"] != "64MiB" {
+			t.Errorf("expected BUFFER default to be '64MiB', got: %v", buffer["default"])
+		}
+		pattern := regexp.MustCompile(str["pattern"].(string))
+		for _, value := range []string{"4096", "64MiB", "1.5GB", "512 KB", "10k", "2b", "0x400", "1_024"} {
+			if _, err := ParseByteSize(value); err != nil {
+				t.Fatalf("expected %q to be a valid byte size, got: %s", value, err)
+			}
+			if !pattern.MatchString(value) {
+				t.Errorf("expected BUFFER pattern to match %q", value)
+			}
+		}
+		for _, value := range []string{"", "-1KB", "1.5", "10 parsecs", "ki"} {
+			if pattern.MatchString(value) {
+				t.Errorf("expected BUFFER pattern not to match %q", value)
+			}
+		}
+	})
+
+	t.Run("pattern and oneof", func(t *testing.T) {
+		level := props["LEVEL"].(map[string]any)
+		if enum, _ := level["enum"].([]any); len(enum) != 3 || enum[0] != "debug" || enum[2] != "warn" {
+			t.Errorf("expected LEVEL enum [debug info warn], got: %v", level["enum"])
+		}
+		region := props["REGION"].(map[string]any)
+		if region["pattern"] != "^[a-z]+-[a-z]+-[0-9]$" {
+			t.Errorf("expected REGION pattern, got: %v", region["pattern"])
+		}
+		workers, _ := schemaAlternatives(t, props["WORKERS"])
+		if enum, _ := workers["enum"].([]any); len(enum) != 3 || enum[0] != float64(1) || enum[2] != float64(4) {
+			t.Errorf("expected WORKERS enum [1 2 4] as numbers, got: %v", workers["enum"])
+		}
+	})
+
+	t.Run("nested structs", func(t *testing.T) {
+		nl, _ := New[nestedSchemaConfig](LoaderConfig{Sources: []sources.Source{&sources.MockSource{SourceName: "test"}}})
+		out, err := nl.JSONSchema()
+		if err != nil {
+			t.Fatalf("expected err to be nil, got: %s", err)
+		}
+		var nested map[string]any
+		if err := json.Unmarshal(out, &nested); err != nil {
+			t.Fatalf("expected valid JSON, got: %s", err)
+		}
+
+		props := nested["properties"].(map[string]any)
+		if _, ok := props["DB_HOST"]; !ok {
+			t.Errorf("expected flattened DB_HOST property, got: %v", props)
+		}
+		db, _ := props["DB"].(map[string]any)
+		if db["type"] != "object" || db["additionalProperties"] != false {
+			t.Fatalf("expected DB to be a closed object, got: %v", props["DB"])
+		}
+		dbProps := db["properties"].(map[string]any)
+		if port, _ := schemaAlternatives(t, dbProps["PORT"]); port["type"] != "integer" {
+			t.Errorf("expected DB.PORT to be integer, got: %v", dbProps["PORT"])
+		}
+		if _, ok := dbProps["TLS"].(map[string]any)["properties"].(map[string]any)["CA"]; !ok {
+			t.Errorf("expected DB.TLS.CA property, got: %v", dbProps["TLS"])
+		}
+
+		required, _ := nested["required"].([]any)
+		if len(required) != 1 || required[0] != "NAME" {
+			t.Errorf("expected required to be [NAME], got: %v", nested["required"])
+		}
+		allOf, _ := nested["allOf"].([]any)
+		if len(allOf) != 1 {
+			t.Fatalf("expected one nested requirement, got: %v", nested["allOf"])
+		}
+		anyOf, _ := allOf[0].(map[string]any)["anyOf"].([]any)
+		if len(anyOf) != 2 || anyOf[0].(map[string]any)["required"].([]any)[0] != "DB_HOST" {
+			t.Errorf("expected DB_HOST to be required flattened or nested, got: %v", allOf[0])
+		}
+	})
+
+	t.Run("invalid tags", func(t *testing.T) {
+		type badConfig struct {
+			Value int `configly:"value,min=abc"`
+		}
+		if _, err := New[badConfig](LoaderConfig{Sources: []sources.Source{&sources.MockSource{SourceName: "test"}}}); err == nil {
+			t.Error("expected error for invalid tag")
+		}
+	})
+}
+
+func TestValidateFile(t *testing.T) {
+	l, _ := New[schemaConfig](LoaderConfig{Sources: []sources.Source{&sources.MockSource{SourceName: "test"}}})
+
+	t.Run("valid YAML file", func(t *testing.T) {
+		path := writeTestFile(t, "config.yaml", "HOST: localhost\nPORT: 0x1F90\nDEBUG: off\nTIMEOUT: 5s\nBUFFER: 1024\n")
+		if err := l.ValidateFile(path); err != nil {
+			t.Errorf("expected err to be nil, got: %s", err)
+		}
+	})
+
+	t.Run("unknown key in YAML reports line", func(t *testing.T) {
+		path := writeTestFile(t, "config.yaml", "HOST: localhost\nPROT: 8080\n")
+		err := l.ValidateFile(path)
+		if err == nil {
+			t.Fatal("expected error for unknown key")
+		}
+		if !strings.Contains(err.Error(), ":2: unknown key PROT") {
+			t.Errorf("expected error to report unknown key on line 2, got: %s", err)
+		}
+	})
+
+	t.Run("type mismatches in YAML", func(t *testing.T) {
+		path := writeTestFile(t, "config.yaml", "HOST:\n  nested: value\nPORT: abc\nDEBUG: maybe\n")
+		err := l.ValidateFile(path)
+		if err == nil {
+			t.Fatal("expected error for type mismatches")
+		}
+		errStr := err.Error()
+		if !strings.Contains(errStr, ":2: unknown key HOST_nested") {
+			t.Errorf("expected unknown flattened key on line 2, got: %s", errStr)
+		}
+		if !strings.Contains(errStr, ":3: PORT: invalid integer") {
+			t.Errorf("expected invalid integer for PORT on line 3, got: %s", errStr)
+		}
+		if !strings.Contains(errStr, ":4: DEBUG: invalid boolean") {
+			t.Errorf("expected invalid boolean for DEBUG on line 4, got: %s", errStr)
+		}
+	})
+
+	t.Run("constraint violations in JSON report line", func(t *testing.T) {
+		path := writeTestFile(t, "config.json", "{\n  \"HOST\": \"localhost\",\n  \"PORT\": 70000\n}")
+		err := l.ValidateFile(path)
+		if err == nil {
+			t.Fatal("expected error for constraint violation")
+		}
+		if !strings.Contains(err.Error(), ":3: PORT: integer value 70000 exceeds maximum 65535") {
+			t.Errorf("expected max violation on line 3, got: %s", err)
+		}
+	})
+
+	t.Run("unknown key and array in JSON", func(t *testing.T) {
+		path := writeTestFile(t, "config.json", "{\n  \"HOST\": [\"a\"],\n  \"EXTRA\": 1\n}")
+		err := l.ValidateFile(path)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		errStr := err.Error()
+		if !strings.Contains(errStr, ":2: HOST: expected string, got array") {
+			t.Errorf("expected array mismatch on line 2, got: %s", errStr)
+		}
+		if !strings.Contains(errStr, ":3: unknown key EXTRA") {
+			t.Errorf("expected unknown key on line 3, got: %s", errStr)
+		}
+	})
+
+	t.Run("JSONC file reports original lines", func(t *testing.T) {
+		path := writeTestFile(t, "config.jsonc", "{\n  // host\n  HOST: 'localhost',\n  /* port\n  */ PORT: 0,\n}")
+		err := l.ValidateFile(path)
+		if err == nil || !strings.Contains(err.Error(), ":5: PORT: integer value 0 is less than minimum 1") {
+			t.Errorf("expected min violation on line 5, got: %v", err)
+		}
+	})
+
+	t.Run("multi-document YAML checks every document", func(t *testing.T) {
+		path := writeTestFile(t, "config.yaml", "HOST: localhost\n---\nPROT: 8080\n")
+		err := l.ValidateFile(path)
+		if err == nil || !strings.Contains(err.Error(), ":3: unknown key PROT") {
+			t.Errorf("expected unknown key on line 3, got: %v", err)
+		}
+	})
+
+	t.Run("env file", func(t *testing.T) {
+		path := writeTestFile(t, ".env", "# comment\nHOST=localhost\n\nexport PORT=abc\nUNKNOWN=1\n")
+		err := l.ValidateFile(path)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		errStr := err.Error()
+		if !strings.Contains(errStr, ":4: PORT: invalid integer") {
+			t.Errorf("expected invalid integer on line 4, got: %s", errStr)
+		}
+		if !strings.Contains(errStr, ":5: unknown key UNKNOWN") {
+			t.Errorf("expected unknown key on line 5, got: %s", errStr)
+		}
+	})
+
+	t.Run("nested documents are flattened", func(t *testing.T) {
+		nl, _ := New[nestedSchemaConfig](LoaderConfig{Sources: []sources.Source{&sources.MockSource{SourceName: "test"}}})
+		yamlPath := writeTestFile(t, "config.yaml", "NAME: app\nDB:\n  HOST: localhost\n  TLS:\n    CA: ca.pem\n")
+		if err := nl.ValidateFile(yamlPath); err != nil {
+			t.Errorf("expected err to be nil, got: %s", err)
+		}
+		jsonPath := writeTestFile(t, "config.json", "{\n  \"DB\": {\n    \"HOST\": \"localhost\",\n    \"PORT\": \"abc\"\n  }\n}")
+		err := nl.ValidateFile(jsonPath)
+		if err == nil || !strings.Contains(err.Error(), ":4: DB_PORT: invalid integer") {
+			t.Errorf("expected invalid integer for DB_PORT on line 4, got: %v", err)
+		}
+	})
+
+	t.Run("key set both flat and nested", func(t *testing.T) {
+		nl, _ := New[nestedSchemaConfig](LoaderConfig{Sources: []sources.Source{&sources.MockSource{SourceName: "test"}}})
+		path := writeTestFile(t, "config.yaml", "DB_HOST: a\nDB:\n  HOST: b\n")
+		err := nl.ValidateFile(path)
+		if err == nil || !strings.Contains(err.Error(), ":3: key DB_HOST is set by both DB_HOST (line 1) and DB.HOST") {
+			t.Errorf("expected collision error on line 3, got: %v", err)
+		}
+	})
+
+	t.Run("encrypted values are not parsed", func(t *testing.T) {
+		path := writeTestFile(t, "config.yaml", "HOST: localhost\nPORT: ENC[bm90IGFuIGludGVnZXI=]\nSECRET: ENC[c2VjcmV0]\n")
+		err := l.ValidateFile(path)
+		if err == nil || !strings.Contains(err.Error(), ":3: unknown key SECRET") {
+			t.Errorf("expected unknown key on line 3, got: %v", err)
+		}
+		if err != nil && strings.Contains(err.Error(), "PORT") {
+			t.Errorf("expected encrypted PORT to be skipped, got: %s", err)
+		}
+	})
+
+	t.Run("unsupported file", func(t *testing.T) {
+		path := writeTestFile(t, "config.txt", "HOST=localhost")
+		if err := l.ValidateFile(path); err == nil {
+			t.Error("expected error for unsupported file type")
+		}
+	})
+}
@@ -0,0 +1,239 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSConfig contains configuration options for creating an AWSClient.
+// Credentials default to the standard AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN environment variables, and
+// the region to AWS_REGION.
+type AWSConfig struct {
+	Region          string       // AWS region (defaults to $AWS_REGION)
+	AccessKeyID     string       // Access key ID (defaults to $AWS_ACCESS_KEY_ID)
+	SecretAccessKey string       // Secret access key (defaults to $AWS_SECRET_ACCESS_KEY)
+	SessionToken    string       // Session token for temporary credentials (defaults to $AWS_SESSION_TOKEN)
+	Endpoint        string       // Endpoint URL overriding the regional service endpoints, e.g. for a local mock
+	HTTPClient      *http.Client // HTTP client used for requests (defaults to http.DefaultClient)
+}
+
+// AWSClient is a minimal client for the SSM Parameter Store and Secrets
+// Manager JSON APIs, signing requests with AWS Signature Version 4.
+// It implements SSMClient.
+type AWSClient struct {
+	cfg    AWSConfig
+	client *http.Client
+	now    func() time.Time
+}
+
+// AWSError is an error returned by an AWS API.
+type AWSError struct {
+	StatusCode int    // HTTP status code of the response
+	Code       string // AWS error code, e.g. "ThrottlingException"
+	Message    string // Error message
+}
+
+// Error returns a description of the AWS error.
+func (e *AWSError) Error() string {
+	return fmt.Sprintf("aws error %s (status %d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// Throttled reports whether the request was rejected because of rate limiting
+// and may succeed if retried later.
+func (e *AWSError) Throttled() bool {
+	switch e.Code {
+	case "ThrottlingException", "TooManyRequestsException", "Throttling", "RequestLimitExceeded":
+		return true
+	}
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+// NewAWSClient creates a new AWS client, filling unset credentials and region
+// from the environment.
+// Returns an error if no region or credentials are available.
+func NewAWSClient(cfg AWSConfig) (*AWSClient, error) {
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_REGION")
+	}
+	if cfg.AccessKeyID == "" && cfg.SecretAccessKey == "" {
+		cfg.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+		cfg.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		cfg.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if cfg.Region == "" {
+		return nil, errors.New("aws region is required")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, errors.New("aws credentials are required")
+	}
+
+	c := &AWSClient{cfg: cfg, client: cfg.HTTPClient, now: time.Now}
+	if c.client == nil {
+		c.client = http.DefaultClient
+	}
+	return c, nil
+}
+
+// GetParametersByPath returns one page of the parameters under path,
+// recursively and with SecureString values decrypted.
+func (c *AWSClient) GetParametersByPath(ctx context.Context, path, nextToken string) ([]SSMParameter, string, error) {
+	req := map[string]any{
+		"Path":           path,
+		"Recursive":      true,
+		"WithDecryption": true,
+	}
+	if nextToken != "" {
+		req["NextToken"] = nextToken
+	}
+	var resp struct {
+		Parameters []SSMParameter `json:"Parameters"`
+		NextToken  string         `json:"NextToken"`
+	}
+	if err := c.call(ctx, "ssm", "AmazonSSM.GetParametersByPath", req, &resp); err != nil {
+		return nil, "", err
+	}
+	return resp.Parameters, resp.NextToken, nil
+}
+
+// GetSecretValue returns the SecretString of a Secrets Manager secret.
+func (c *AWSClient) GetSecretValue(ctx context.Context, secretID string) (string, error) {
+	var resp struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := c.call(ctx, "secretsmanager", "secretsmanager.GetSecretValue", map[string]string{"SecretId": secretID}, &resp); err != nil {
+		return "", err
+	}
+	return resp.SecretString, nil
+}
+
+// call sends a signed AWS JSON 1.1 request for target to service and decodes
+// the response into out. Error responses are returned as *AWSError.
+func (c *AWSClient) call(ctx context.Context, service, target string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("error encoding %s request: %w", target, err)
+	}
+	endpoint := c.cfg.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.%s.amazonaws.com", service, c.cfg.Region)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating %s request: %w", target, err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	c.sign(req, service, body)
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting %s: %w", target, err)
+	}
+	defer res.Body.Close()
+
+	respBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return fmt.Errorf("error reading %s response: %w", target, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		var awsErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(respBody, &awsErr)
+		// error types may be namespaced, e.g. "com.amazonaws.ssm#ThrottlingException"
+		code := awsErr.Type[strings.LastIndex(awsErr.Type, "#")+1:]
+		return &AWSError{StatusCode: res.StatusCode, Code: code, Message: awsErr.Message}
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("error decoding %s response: %w", target, err)
+	}
+	return nil
+}
+
+// sign adds AWS Signature Version 4 headers to req.
+func (c *AWSClient) sign(req *http.Request, service string, body []byte) {
+	now := c.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if c.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.cfg.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+	payloadHash := sha256.Sum256(body)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURIPath(req.URL),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, c.cfg.Region, service)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, c.cfg.Region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalURIPath returns the URI-encoded path used in the canonical request.
+func canonicalURIPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	return path
+}
+
+// hmacSHA256 computes HMAC-SHA256 of data with key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,84 @@
+package sources
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewAWSClient(t *testing.T) {
+	t.Run("read credentials from environment", func(t *testing.T) {
+		t.Setenv("AWS_REGION", "eu-west-1")
+		t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+		t.Setenv("AWS_SESSION_TOKEN", "session")
+
+		client, err := NewAWSClient(AWSConfig{})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if client.cfg.Region != "eu-west-1" || client.cfg.AccessKeyID != "AKID" || client.cfg.SessionToken != "session" {
+			t.Errorf("expected config from environment, got: %+v", client.cfg)
+		}
+	})
+
+	t.Run("error without region", func(t *testing.T) {
+		t.Setenv("AWS_REGION", "")
+		if _, err := NewAWSClient(AWSConfig{AccessKeyID: "AKID", SecretAccessKey: "secret"}); err == nil {
+			t.Error("expected error without region")
+		}
+	})
+
+	t.Run("error without credentials", func(t *testing.T) {
+		t.Setenv("AWS_ACCESS_KEY_ID", "")
+		t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+		if _, err := NewAWSClient(AWSConfig{Region: "us-east-1"}); err == nil {
+			t.Error("expected error without credentials")
+		}
+	})
+}
+
+func TestAWSClient_sign(t *testing.T) {
+	// example request and signature from the AWS Signature Version 4 documentation
+	client := &AWSClient{
+		cfg: AWSConfig{
+			Region:          "us-east-1",
+			AccessKeyID:     "AKIDEXAMPLE",
+			SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		},
+		now: func() time.Time { return time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC) },
+	}
+	req, err := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %s", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+
+	client.sign(req, "iam", nil)
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if auth := req.Header.Get("Authorization"); auth != expected {
+		t.Errorf("expected Authorization to be '%s', got: %s", expected, auth)
+	}
+	if date := req.Header.Get("X-Amz-Date"); date != "20150830T123600Z" {
+		t.Errorf("expected X-Amz-Date to be '20150830T123600Z', got: %s", date)
+	}
+}
+
+func TestAWSError_Throttled(t *testing.T) {
+	tests := []struct {
+		err      *AWSError
+		expected bool
+	}{
+		{err: &AWSError{StatusCode: 400, Code: "ThrottlingException"}, expected: true},
+		{err: &AWSError{StatusCode: 429, Code: "Unknown"}, expected: true},
+		{err: &AWSError{StatusCode: 400, Code: "ParameterNotFound"}, expected: false},
+	}
+	for _, tt := range tests {
+		if got := tt.err.Throttled(); got != tt.expected {
+			t.Errorf("expected Throttled() to be %t for %s, got: %t", tt.expected, tt.err.Code, got)
+		}
+	}
+}
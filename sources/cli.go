@@ -1,7 +1,9 @@
 package sources
 
 import (
+	"maps"
 	"os"
+	"slices"
 	"strings"
 )
 
@@ -50,3 +52,13 @@ func (s *CLISource) GetValue(key string) (string, bool, error) {
 	val, found := s.flags[key]
 	return val, found, nil
 }
+
+// Keys returns all flag names parsed from the command line, sorted.
+func (s *CLISource) Keys() []string {
+	return slices.Sorted(maps.Keys(s.flags))
+}
+
+// StrictKeys returns all flag names parsed from the command line, sorted.
+func (s *CLISource) StrictKeys() []string {
+	return s.Keys()
+}
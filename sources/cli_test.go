@@ -364,3 +364,12 @@ func TestCLISource_Integration(t *testing.T) {
 		t.Errorf("expected empty value, got: %s", val)
 	}
 }
+
+func TestCLISource_Keys(t *testing.T) {
+	source := FromCLIArgs([]string{"--port=8080", "-host=localhost", "positional"}).(*CLISource)
+
+	keys := source.Keys()
+	if len(keys) != 2 || keys[0] != "host" || keys[1] != "port" {
+		t.Errorf("expected keys [host port], got: %v", keys)
+	}
+}
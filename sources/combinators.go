@@ -0,0 +1,478 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prefixed returns a source that looks up prefix+key in src, e.g. PORT is
+// read from APP_PORT for prefix "APP_". Only keys starting with prefix are
+// listed, with the prefix removed.
+func Prefixed(src Source, prefix string) Source {
+	return withCapabilities(&prefixedSource{src: src, prefix: prefix}, isLister(src), isWatcher(src))
+}
+
+// Mapped returns a source that looks up keyFunc(key) in src, e.g. with
+// strings.ToLower to read PORT from a file holding "port".
+func Mapped(src Source, keyFunc func(key string) string) Source {
+	return withCapabilities(&mappedSource{src: src, keyFunc: keyFunc}, false, isWatcher(src))
+}
+
+// Fallback returns a source that looks up a key in primary and, if it is not
+// found there, in secondary.
+func Fallback(primary, secondary Source) Source {
+	return withCapabilities(&fallbackSource{primary: primary, secondary: secondary},
+		isLister(primary) || isLister(secondary), isWatcher(primary) || isWatcher(secondary))
+}
+
+// Optional returns src, or an empty source if err is not nil. It accepts the
+// results of a source constructor directly, so that a missing optional file
+// does not prevent startup:
+//
+//	sources.Optional(sources.FromFile("config.local.yaml"))
+func Optional(src Source, err error) Source {
+	if err != nil {
+		return withCapabilities(&optionalSource{err: err}, true, false)
+	}
+	return withCapabilities(&optionalSource{src: src}, isLister(src), isWatcher(src))
+}
+
+// Static returns a source named "static" holding a fixed set of values.
+func Static(values map[string]string) Source {
+	return FromMapWithName("static", values)
+}
+
+// Cached returns a source that caches lookups in src, including keys that
+// were not found, for ttl. Lookups that fail are not cached. If src is a
+// Watcher, the cache is cleared whenever src reports a change.
+func Cached(src Source, ttl time.Duration) Source {
+	cached := &cachedSource{src: src, ttl: ttl, entries: make(map[string]cacheEntry), now: time.Now}
+	return withCapabilities(cached, isLister(src), isWatcher(src))
+}
+
+// combinator is implemented by the sources returned by the combinators. Their
+// keys, strictKeys, and watch methods are only exposed as KeyLister,
+// StrictKeyLister, and Watcher (see withCapabilities) when the wrapped sources
+// support listing keys and watching, so that wrapping a source does not change
+// what it supports.
+type combinator interface {
+	Source
+	Wrapper
+	keys() []string
+	strictKeys() []string
+	watch(ctx context.Context, onChange func()) error
+}
+
+// withCapabilities returns c as a Source that is also a KeyLister and
+// StrictKeyLister if lists is set and a Watcher if watches is set.
+func withCapabilities(c combinator, lists, watches bool) Source {
+	switch {
+	case lists && watches:
+		return listingWatchingSource{c}
+	case lists:
+		return listingSource{c}
+	case watches:
+		return watchingSource{c}
+	}
+	return c
+}
+
+// listingSource exposes a combinator as a KeyLister and StrictKeyLister.
+type listingSource struct{ combinator }
+
+// Keys returns the combinator's keys.
+func (s listingSource) Keys() []string { return s.keys() }
+
+// StrictKeys returns the combinator's keys that strict mode checks.
+func (s listingSource) StrictKeys() []string { return s.strictKeys() }
+
+// watchingSource exposes a combinator as a Watcher.
+type watchingSource struct{ combinator }
+
+// Watch watches the combinator's sources.
+func (s watchingSource) Watch(ctx context.Context, onChange func()) error {
+	return s.watch(ctx, onChange)
+}
+
+// listingWatchingSource exposes a combinator as a KeyLister, a
+// StrictKeyLister, and a Watcher.
+type listingWatchingSource struct{ combinator }
+
+// Keys returns the combinator's keys.
+func (s listingWatchingSource) Keys() []string { return s.keys() }
+
+// StrictKeys returns the combinator's keys that strict mode checks.
+func (s listingWatchingSource) StrictKeys() []string { return s.strictKeys() }
+
+// Watch watches the combinator's sources.
+func (s listingWatchingSource) Watch(ctx context.Context, onChange func()) error {
+	return s.watch(ctx, onChange)
+}
+
+// prefixedSource implements Prefixed.
+type prefixedSource struct {
+	src    Source
+	prefix string
+}
+
+// Name returns the name of the wrapped source.
+func (s *prefixedSource) Name() string {
+	return s.src.Name()
+}
+
+// GetValue retrieves prefix+key from the wrapped source.
+func (s *prefixedSource) GetValue(key string) (string, bool, error) {
+	return s.src.GetValue(s.prefix + key)
+}
+
+// Underlying returns the wrapped source and the prefixed key.
+func (s *prefixedSource) Underlying(key string) (Source, string, bool) {
+	return s.src, s.prefix + key, true
+}
+
+// keys returns the wrapped source's keys that start with the prefix, with the
+// prefix removed, sorted.
+func (s *prefixedSource) keys() []string {
+	return s.trimPrefix(listKeys(s.src))
+}
+
+// strictKeys returns the wrapped source's strict keys that start with the
+// prefix, with the prefix removed, sorted.
+func (s *prefixedSource) strictKeys() []string {
+	return s.trimPrefix(listStrictKeys(s.src))
+}
+
+// trimPrefix returns the keys that start with the prefix, with the prefix
+// removed.
+func (s *prefixedSource) trimPrefix(keys []string) []string {
+	var trimmed []string
+	for _, key := range keys {
+		if rest, ok := strings.CutPrefix(key, s.prefix); ok && rest != "" {
+			trimmed = append(trimmed, rest)
+		}
+	}
+	return trimmed
+}
+
+// watch watches the wrapped source.
+func (s *prefixedSource) watch(ctx context.Context, onChange func()) error {
+	return watchAll(ctx, onChange, s.src)
+}
+
+// mappedSource implements Mapped. It does not list keys, since keyFunc
+// cannot be inverted.
+type mappedSource struct {
+	src     Source
+	keyFunc func(key string) string
+}
+
+// Name returns the name of the wrapped source.
+func (s *mappedSource) Name() string {
+	return s.src.Name()
+}
+
+// GetValue retrieves keyFunc(key) from the wrapped source.
+func (s *mappedSource) GetValue(key string) (string, bool, error) {
+	return s.src.GetValue(s.keyFunc(key))
+}
+
+// Underlying returns the wrapped source and the mapped key.
+func (s *mappedSource) Underlying(key string) (Source, string, bool) {
+	return s.src, s.keyFunc(key), true
+}
+
+// keys returns nil; Mapped never exposes it as a KeyLister.
+func (s *mappedSource) keys() []string {
+	return nil
+}
+
+// strictKeys returns nil; Mapped never exposes it as a StrictKeyLister.
+func (s *mappedSource) strictKeys() []string {
+	return nil
+}
+
+// watch watches the wrapped source.
+func (s *mappedSource) watch(ctx context.Context, onChange func()) error {
+	return watchAll(ctx, onChange, s.src)
+}
+
+// fallbackSource implements Fallback.
+type fallbackSource struct {
+	primary   Source
+	secondary Source
+	mu        sync.Mutex
+	answered  map[string]Source // Source that held each key at its last lookup
+}
+
+// Name returns the names of both sources.
+func (s *fallbackSource) Name() string {
+	return fmt.Sprintf("fallback(%s, %s)", s.primary.Name(), s.secondary.Name())
+}
+
+// GetValue retrieves key from the primary source, then the secondary source.
+// An error from the primary source is returned without consulting the
+// secondary source.
+func (s *fallbackSource) GetValue(key string) (string, bool, error) {
+	val, found, err := s.primary.GetValue(key)
+	if err != nil || found {
+		s.remember(key, s.primary, found && err == nil)
+		return val, found, err
+	}
+	val, found, err = s.secondary.GetValue(key)
+	s.remember(key, s.secondary, found && err == nil)
+	return val, found, err
+}
+
+// remember records src as the source holding key if found is set, or forgets
+// the key otherwise.
+func (s *fallbackSource) remember(key string, src Source, found bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !found {
+		delete(s.answered, key)
+		return
+	}
+	if s.answered == nil {
+		s.answered = make(map[string]Source)
+	}
+	s.answered[key] = src
+}
+
+// Underlying returns the source that held key at the last lookup, querying
+// both sources only if key has not been found before.
+func (s *fallbackSource) Underlying(key string) (Source, string, bool) {
+	s.mu.Lock()
+	src, ok := s.answered[key]
+	s.mu.Unlock()
+	if ok {
+		return src, key, true
+	}
+	for _, src := range []Source{s.primary, s.secondary} {
+		if _, found, err := src.GetValue(key); err == nil && found {
+			return src, key, true
+		}
+	}
+	return nil, "", false
+}
+
+// keys returns the keys of both sources, sorted.
+func (s *fallbackSource) keys() []string {
+	keys := append(listKeys(s.primary), listKeys(s.secondary)...)
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// strictKeys returns the union of both sources' strict keys, sorted.
+func (s *fallbackSource) strictKeys() []string {
+	keys := append(listStrictKeys(s.primary), listStrictKeys(s.secondary)...)
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// watch watches both sources.
+func (s *fallbackSource) watch(ctx context.Context, onChange func()) error {
+	return watchAll(ctx, onChange, s.primary, s.secondary)
+}
+
+// optionalSource implements Optional. When construction failed, src is nil
+// and the source is empty.
+type optionalSource struct {
+	src Source
+	err error
+}
+
+// Name returns the name of the wrapped source, or "optional" if it failed to
+// construct.
+func (s *optionalSource) Name() string {
+	if s.src == nil {
+		return "optional"
+	}
+	return s.src.Name()
+}
+
+// GetValue retrieves key from the wrapped source, if any.
+func (s *optionalSource) GetValue(key string) (string, bool, error) {
+	if s.src == nil {
+		return "", false, nil
+	}
+	return s.src.GetValue(key)
+}
+
+// Underlying returns the wrapped source, if any.
+func (s *optionalSource) Underlying(key string) (Source, string, bool) {
+	return s.src, key, s.src != nil
+}
+
+// keys returns the keys of the wrapped source, if any.
+func (s *optionalSource) keys() []string {
+	if s.src == nil {
+		return nil
+	}
+	return listKeys(s.src)
+}
+
+// strictKeys returns the wrapped source's strict keys, or nil if there is
+// none.
+func (s *optionalSource) strictKeys() []string {
+	if s.src == nil {
+		return nil
+	}
+	return listStrictKeys(s.src)
+}
+
+// watch watches the wrapped source. Optional only exposes it as a Watcher
+// when there is a wrapped source.
+func (s *optionalSource) watch(ctx context.Context, onChange func()) error {
+	return watchAll(ctx, onChange, s.src)
+}
+
+// cachedSource implements Cached.
+type cachedSource struct {
+	src     Source
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	now     func() time.Time
+}
+
+// cacheEntry is a cached lookup result, including the source and key that
+// held the value (see Wrapper), so that resolving provenance does not query
+// the wrapped source again.
+type cacheEntry struct {
+	val        string
+	found      bool
+	expires    time.Time
+	underlying Source
+	key        string
+}
+
+// Name returns the name of the wrapped source.
+func (s *cachedSource) Name() string {
+	return s.src.Name()
+}
+
+// GetValue retrieves key from the cache, or from the wrapped source if the
+// cached entry is missing or expired.
+func (s *cachedSource) GetValue(key string) (string, bool, error) {
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	s.mu.Unlock()
+	if ok && s.now().Before(entry.expires) {
+		return entry.val, entry.found, nil
+	}
+
+	val, found, err := s.src.GetValue(key)
+	if err != nil {
+		return "", false, err
+	}
+	entry = cacheEntry{val: val, found: found, expires: s.now().Add(s.ttl), underlying: s.src, key: key}
+	if wrapper, ok := s.src.(Wrapper); ok && found {
+		if underlying, underlyingKey, ok := wrapper.Underlying(key); ok {
+			entry.underlying, entry.key = underlying, underlyingKey
+		}
+	}
+	s.mu.Lock()
+	s.entries[key] = entry
+	s.mu.Unlock()
+	return val, found, nil
+}
+
+// Underlying returns the source and key that held key when it was cached, or
+// the wrapped source if key is not cached.
+func (s *cachedSource) Underlying(key string) (Source, string, bool) {
+	s.mu.Lock()
+	entry, ok := s.entries[key]
+	s.mu.Unlock()
+	if ok && entry.underlying != nil {
+		return entry.underlying, entry.key, true
+	}
+	return s.src, key, true
+}
+
+// keys returns the keys of the wrapped source.
+func (s *cachedSource) keys() []string {
+	return listKeys(s.src)
+}
+
+// strictKeys returns the wrapped source's strict keys.
+func (s *cachedSource) strictKeys() []string {
+	return listStrictKeys(s.src)
+}
+
+// watch watches the wrapped source, clearing the cache before calling
+// onChange.
+func (s *cachedSource) watch(ctx context.Context, onChange func()) error {
+	return watchAll(ctx, func() {
+		s.mu.Lock()
+		clear(s.entries)
+		s.mu.Unlock()
+		onChange()
+	}, s.src)
+}
+
+// isLister reports whether src can list its keys.
+func isLister(src Source) bool {
+	_, ok := src.(KeyLister)
+	return ok
+}
+
+// isWatcher reports whether src can be watched.
+func isWatcher(src Source) bool {
+	_, ok := src.(Watcher)
+	return ok
+}
+
+// listKeys returns the keys of src if it is a KeyLister, or nil otherwise.
+func listKeys(src Source) []string {
+	if lister, ok := src.(KeyLister); ok {
+		return lister.Keys()
+	}
+	return nil
+}
+
+// listStrictKeys returns the strict keys of src if it is a StrictKeyLister,
+// or nil otherwise.
+func listStrictKeys(src Source) []string {
+	if lister, ok := src.(StrictKeyLister); ok {
+		return lister.StrictKeys()
+	}
+	return nil
+}
+
+// watchAll watches every source in srcs that is a Watcher, blocking until ctx
+// is cancelled or a watcher fails. With no watchers it simply waits for ctx.
+func watchAll(ctx context.Context, onChange func(), srcs ...Source) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(srcs))
+	watching := 0
+	for _, src := range srcs {
+		if watcher, ok := src.(Watcher); ok {
+			watching++
+			go func() {
+				errs <- watcher.Watch(ctx, onChange)
+			}()
+		}
+	}
+
+	var watchErr error
+	if watching == 0 {
+		<-ctx.Done()
+	}
+	for range watching {
+		err := <-errs
+		if watchErr == nil && err != nil && ctx.Err() == nil {
+			watchErr = err
+			cancel()
+		}
+	}
+	if watchErr != nil {
+		return watchErr
+	}
+	return ctx.Err()
+}
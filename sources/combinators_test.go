@@ -0,0 +1,244 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrefixed(t *testing.T) {
+	inner := &MockSource{SourceName: "env", Values: map[string]string{"APP_PORT": "8080", "APP_": "x", "OTHER": "y"}}
+	source := Prefixed(inner, "APP_")
+
+	if val, found, _ := source.GetValue("PORT"); !found || val != "8080" {
+		t.Errorf("expected 'PORT' to be '8080', got: %q", val)
+	}
+	if _, found, _ := source.GetValue("OTHER"); found {
+		t.Error("expected unprefixed key not to be found")
+	}
+	if keys := source.(KeyLister).Keys(); !reflect.DeepEqual(keys, []string{"PORT"}) {
+		t.Errorf("expected keys [PORT], got: %v", keys)
+	}
+	if keys := source.(StrictKeyLister).StrictKeys(); !reflect.DeepEqual(keys, []string{"PORT"}) {
+		t.Errorf("expected strict keys [PORT], got: %v", keys)
+	}
+	if source.Name() != "env" {
+		t.Errorf("expected name 'env', got: %s", source.Name())
+	}
+	underlying, key, ok := source.(Wrapper).Underlying("PORT")
+	if !ok || underlying != inner || key != "APP_PORT" {
+		t.Errorf("expected underlying env/APP_PORT, got: %v %s %t", underlying, key, ok)
+	}
+}
+
+func TestMapped(t *testing.T) {
+	inner := &MockSource{SourceName: "file", Values: map[string]string{"port": "8080"}}
+	source := Mapped(inner, strings.ToLower)
+
+	if val, found, _ := source.GetValue("PORT"); !found || val != "8080" {
+		t.Errorf("expected 'PORT' to be '8080', got: %q", val)
+	}
+	if _, ok := source.(KeyLister); ok {
+		t.Error("expected mapped source not to list keys")
+	}
+	if _, key, _ := source.(Wrapper).Underlying("PORT"); key != "port" {
+		t.Errorf("expected underlying key 'port', got: %s", key)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	primary := &MockSource{SourceName: "primary", Values: map[string]string{"A": "1"}}
+	secondary := &MockSource{SourceName: "secondary", Values: map[string]string{"A": "2", "B": "3"}}
+	source := Fallback(primary, secondary)
+
+	t.Run("get values in priority order", func(t *testing.T) {
+		if val, _, _ := source.GetValue("A"); val != "1" {
+			t.Errorf("expected 'A' from primary, got: %q", val)
+		}
+		if val, _, _ := source.GetValue("B"); val != "3" {
+			t.Errorf("expected 'B' from secondary, got: %q", val)
+		}
+		if _, found, _ := source.GetValue("C"); found {
+			t.Error("expected 'C' not to be found")
+		}
+	})
+
+	t.Run("report underlying source", func(t *testing.T) {
+		if underlying, _, _ := source.(Wrapper).Underlying("B"); underlying != secondary {
+			t.Errorf("expected 'B' to come from secondary, got: %v", underlying)
+		}
+		if _, _, ok := source.(Wrapper).Underlying("C"); ok {
+			t.Error("expected no underlying source for missing key")
+		}
+	})
+
+	t.Run("list keys of both sources", func(t *testing.T) {
+		if keys := source.(KeyLister).Keys(); !reflect.DeepEqual(keys, []string{"A", "B"}) {
+			t.Errorf("expected keys [A B], got: %v", keys)
+		}
+	})
+
+	t.Run("list strict keys of strict sources only", func(t *testing.T) {
+		mixed := Fallback(&MockSource{SourceName: "file", Values: map[string]string{"A": "1"}}, FromMap(map[string]string{"B": "2"}))
+		if keys := mixed.(StrictKeyLister).StrictKeys(); !reflect.DeepEqual(keys, []string{"A"}) {
+			t.Errorf("expected strict keys [A], got: %v", keys)
+		}
+	})
+
+	t.Run("return primary error", func(t *testing.T) {
+		failing := Fallback(&MockSource{SourceName: "failing", Err: errors.New("unavailable")}, secondary)
+		if _, _, err := failing.GetValue("B"); err == nil {
+			t.Error("expected primary error to be returned")
+		}
+	})
+}
+
+func TestOptional(t *testing.T) {
+	t.Run("empty source on error", func(t *testing.T) {
+		source := Optional(FromFile("missing.yaml"))
+		if _, found, err := source.GetValue("A"); found || err != nil {
+			t.Errorf("expected empty source, got found=%t err=%v", found, err)
+		}
+		if keys := source.(KeyLister).Keys(); len(keys) != 0 {
+			t.Errorf("expected no keys, got: %v", keys)
+		}
+	})
+
+	t.Run("wrapped source on success", func(t *testing.T) {
+		inner := &MockSource{SourceName: "mock", Values: map[string]string{"A": "1"}}
+		source := Optional(inner, nil)
+		if val, _, _ := source.GetValue("A"); val != "1" {
+			t.Errorf("expected 'A' to be '1', got: %q", val)
+		}
+		if source.Name() != "mock" {
+			t.Errorf("expected name 'mock', got: %s", source.Name())
+		}
+	})
+}
+
+func TestStatic(t *testing.T) {
+	values := map[string]string{"A": "1"}
+	source := Static(values)
+	values["A"] = "changed"
+
+	if val, _, _ := source.GetValue("A"); val != "1" {
+		t.Errorf("expected 'A' to be '1', got: %q", val)
+	}
+	if source.Name() != "static" {
+		t.Errorf("expected name 'static', got: %s", source.Name())
+	}
+}
+
+// countingSource counts GetValue calls.
+type countingSource struct {
+	MockSource
+	calls int
+}
+
+func (s *countingSource) GetValue(key string) (string, bool, error) {
+	s.calls++
+	return s.MockSource.GetValue(key)
+}
+
+func TestCached(t *testing.T) {
+	inner := &countingSource{MockSource: MockSource{SourceName: "remote", Values: map[string]string{"A": "1"}}}
+	source := Cached(inner, time.Minute).(listingSource).combinator.(*cachedSource)
+	now := time.Now()
+	source.now = func() time.Time { return now }
+
+	source.GetValue("A")
+	source.GetValue("A")
+	source.GetValue("missing")
+	source.GetValue("missing")
+	if inner.calls != 2 {
+		t.Errorf("expected 2 lookups within ttl, got: %d", inner.calls)
+	}
+
+	now = now.Add(2 * time.Minute)
+	inner.Values["A"] = "2"
+	if val, _, _ := source.GetValue("A"); val != "2" {
+		t.Errorf("expected refreshed value '2' after ttl, got: %q", val)
+	}
+
+	inner.Err = errors.New("unavailable")
+	now = now.Add(2 * time.Minute)
+	if _, _, err := source.GetValue("A"); err == nil {
+		t.Error("expected error to be returned")
+	}
+	inner.Err = nil
+	if val, _, err := source.GetValue("A"); err != nil || val != "2" {
+		t.Errorf("expected errors not to be cached, got: %q %v", val, err)
+	}
+}
+
+// changingSource is a MockSource whose Watch reports a single change.
+type changingSource struct {
+	MockSource
+}
+
+func (s *changingSource) Watch(ctx context.Context, onChange func()) error {
+	s.Values["A"] = "2"
+	onChange()
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestCombinatorWatch(t *testing.T) {
+	inner := &changingSource{MockSource{SourceName: "watched", Values: map[string]string{"A": "1"}}}
+	source := Cached(Prefixed(inner, ""), time.Hour)
+	source.GetValue("A")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- source.(Watcher).Watch(ctx, func() { close(changed) })
+	}()
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected onChange to be called through the combinators")
+	}
+	if val, _, _ := source.GetValue("A"); val != "2" {
+		t.Errorf("expected cache to be cleared on change, got: %q", val)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got: %v", err)
+	}
+}
+
+func TestCombinatorCapabilities(t *testing.T) {
+	plain := FromMap(map[string]string{"A": "1"})
+	watched := &changingSource{MockSource{SourceName: "watched"}}
+	tests := []struct {
+		name    string
+		source  Source
+		lists   bool
+		watches bool
+	}{
+		{"prefixed", Prefixed(plain, "APP_"), true, false},
+		{"prefixed watcher", Prefixed(watched, "APP_"), true, true},
+		{"mapped", Mapped(plain, strings.ToLower), false, false},
+		{"fallback", Fallback(plain, watched), true, true},
+		{"optional", Optional(plain, nil), true, false},
+		{"optional error", Optional(nil, errors.New("missing")), true, false},
+		{"cached", Cached(plain, time.Minute), true, false},
+		{"cached watcher", Cached(watched, time.Minute), true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := tt.source.(KeyLister); ok != tt.lists {
+				t.Errorf("expected KeyLister to be %t, got: %t", tt.lists, ok)
+			}
+			if _, ok := tt.source.(Watcher); ok != tt.watches {
+				t.Errorf("expected Watcher to be %t, got: %t", tt.watches, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultConsulWaitTime is how long a Consul blocking query waits for a
+	// change when ConsulConfig.WaitTime is not set.
+	DefaultConsulWaitTime = 5 * time.Minute
+	// defaultWatchRetryInterval is how long Consul and etcd watches wait
+	// before retrying after a failed request.
+	defaultWatchRetryInterval = 5 * time.Second
+)
+
+// ConsulConfig contains configuration options for creating a ConsulSource.
+type ConsulConfig struct {
+	Address    string // Consul HTTP address (e.g. http://127.0.0.1:8500)
+	Prefix     string // KV folder to load (e.g. "config/app"); a trailing "/" is implied
+	Token      string // ACL token (optional)
+	Datacenter string // Datacenter to query (optional, defaults to the agent's)
+	// KeyFunc maps a KV key, relative to Prefix, to a configly key
+	// (defaults to the relative key unchanged).
+	KeyFunc    func(key string) string
+	WaitTime   time.Duration // Maximum blocking query wait used by Watch (defaults to DefaultConsulWaitTime)
+	HTTPClient *http.Client  // HTTP client used for requests (defaults to http.DefaultClient)
+}
+
+// ConsulSource is a configuration source backed by the Consul KV store.
+// All keys under the prefix are fetched in a single recursive request, and
+// Watch uses Consul blocking queries to pick up changes.
+type ConsulSource struct {
+	mu            sync.RWMutex
+	cfg           ConsulConfig
+	client        *http.Client
+	kvMap         map[string]string
+	index         uint64
+	retryInterval time.Duration
+}
+
+// consulKV is a single entry of a Consul KV list response.
+type consulKV struct {
+	Key   string `json:"Key"`
+	Value []byte `json:"Value"` // base64 in JSON, decoded by encoding/json
+}
+
+// FromConsul creates a new Consul KV configuration source and fetches all
+// keys under the configured prefix.
+// Returns an error if the address is missing or the initial fetch fails.
+func FromConsul(cfg ConsulConfig) (*ConsulSource, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("consul address is required")
+	}
+	if cfg.WaitTime <= 0 {
+		cfg.WaitTime = DefaultConsulWaitTime
+	}
+	cfg.Address = strings.TrimRight(cfg.Address, "/")
+	// Treat the prefix as a folder so that keys are relative to it and
+	// "config/app" does not also match "config/application/...".
+	if cfg.Prefix = strings.Trim(cfg.Prefix, "/"); cfg.Prefix != "" {
+		cfg.Prefix += "/"
+	}
+
+	s := &ConsulSource{
+		cfg:           cfg,
+		client:        cfg.HTTPClient,
+		retryInterval: defaultWatchRetryInterval,
+	}
+	if s.client == nil {
+		s.client = http.DefaultClient
+	}
+
+	kvMap, index, err := s.fetch(context.Background(), 0)
+	if err != nil {
+		return nil, err
+	}
+	s.kvMap = kvMap
+	s.index = index
+	return s, nil
+}
+
+// fetch lists every key under the prefix. When index is non-zero the request
+// is a blocking query that returns once the prefix changes past index or the
+// wait time elapses. Returns the key-value pairs and the new Consul index, or
+// 0 if the response has no valid X-Consul-Index header.
+func (s *ConsulSource) fetch(ctx context.Context, index uint64) (map[string]string, uint64, error) {
+	query := url.Values{"recurse": {"true"}}
+	if s.cfg.Datacenter != "" {
+		query.Set("dc", s.cfg.Datacenter)
+	}
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", s.cfg.WaitTime.String())
+	}
+	reqURL := fmt.Sprintf("%s/v1/kv/%s?%s", s.cfg.Address, s.cfg.Prefix, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating consul request: %w", err)
+	}
+	if s.cfg.Token != "" {
+		req.Header.Set("X-Consul-Token", s.cfg.Token)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error requesting consul prefix %s: %w", s.cfg.Prefix, err)
+	}
+	defer res.Body.Close()
+
+	newIndex, err := strconv.ParseUint(res.Header.Get("X-Consul-Index"), 10, 64)
+	if err != nil {
+		logger.Warn().Err(err).Str("prefix", s.cfg.Prefix).Msg("consul response has no valid X-Consul-Index")
+		newIndex = 0
+	}
+	kvMap := make(map[string]string)
+	switch res.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		// an empty prefix is not an error
+		return kvMap, newIndex, nil
+	default:
+		return nil, 0, fmt.Errorf("consul error for prefix %s (status %d)", s.cfg.Prefix, res.StatusCode)
+	}
+
+	var entries []consulKV
+	if err := json.NewDecoder(res.Body).Decode(&entries); err != nil {
+		return nil, 0, fmt.Errorf("error decoding consul response: %w", err)
+	}
+	for _, entry := range entries {
+		key := strings.TrimPrefix(entry.Key, s.cfg.Prefix)
+		// folders are returned as keys ending in "/" without a value
+		if key == "" || strings.HasSuffix(key, "/") {
+			continue
+		}
+		if s.cfg.KeyFunc != nil {
+			key = s.cfg.KeyFunc(key)
+		}
+		kvMap[key] = string(entry.Value)
+	}
+	return kvMap, newIndex, nil
+}
+
+// Name returns the name of this source.
+func (s *ConsulSource) Name() string {
+	return fmt.Sprintf("consul:%s/%s", s.cfg.Address, s.cfg.Prefix)
+}
+
+// GetValue retrieves a value by key from the last fetched snapshot of the prefix.
+func (s *ConsulSource) GetValue(key string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, found := s.kvMap[key]
+	return val, found, nil
+}
+
+// Keys returns all keys under the prefix, sorted.
+func (s *ConsulSource) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Sorted(maps.Keys(s.kvMap))
+}
+
+// Watch issues blocking queries against the prefix and calls onChange
+// whenever the set of keys or any value changes. Failed requests, and
+// responses without an index to block on, are retried after a short delay.
+// Returns ctx.Err() once ctx is cancelled.
+func (s *ConsulSource) Watch(ctx context.Context, onChange func()) error {
+	for {
+		s.mu.RLock()
+		index := s.index
+		s.mu.RUnlock()
+
+		kvMap, newIndex, err := s.fetch(ctx, max(index, 1))
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			if !sleepContext(ctx, s.retryInterval) {
+				return ctx.Err()
+			}
+			continue
+		}
+		// without a new index, keep the previous one and back off below,
+		// since the next query might not block
+		missingIndex := newIndex == 0
+		if missingIndex {
+			newIndex = index
+		}
+		// per the Consul docs, reset the index if it goes backwards
+		if newIndex < index {
+			newIndex = 0
+		}
+
+		s.mu.Lock()
+		changed := !maps.Equal(s.kvMap, kvMap)
+		s.kvMap = kvMap
+		s.index = newIndex
+		s.mu.Unlock()
+		if changed {
+			onChange()
+		}
+		if missingIndex && !sleepContext(ctx, s.retryInterval) {
+			return ctx.Err()
+		}
+	}
+}
+
+// sleepContext waits for d or until ctx is cancelled.
+// Returns false if ctx was cancelled first.
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
@@ -0,0 +1,253 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConsul is an in-process stand-in for the Consul KV HTTP API,
+// including blocking queries.
+type fakeConsul struct {
+	mu      sync.Mutex
+	kv      map[string]string
+	index   uint64
+	changed chan struct{} // closed and replaced on every write
+	token   string
+}
+
+func newFakeConsul(kv map[string]string) *fakeConsul {
+	return &fakeConsul{kv: kv, index: 10, changed: make(chan struct{})}
+}
+
+func (f *fakeConsul) set(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.kv[key] = value
+	f.index++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if f.token != "" && r.Header.Get("X-Consul-Token") != f.token {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	prefix := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+
+	f.mu.Lock()
+	if index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64); index >= f.index {
+		changed := f.changed
+		f.mu.Unlock()
+		wait, _ := time.ParseDuration(r.URL.Query().Get("wait"))
+		select {
+		case <-changed:
+		case <-time.After(wait):
+		case <-r.Context().Done():
+			return
+		}
+		f.mu.Lock()
+	}
+	defer f.mu.Unlock()
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(f.index, 10))
+	var entries []consulKV
+	for key, value := range f.kv {
+		if strings.HasPrefix(key, prefix) {
+			entries = append(entries, consulKV{Key: key, Value: []byte(value)})
+		}
+	}
+	if len(entries) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(entries)
+}
+
+func TestFromConsul(t *testing.T) {
+	t.Run("fetch prefix in one request", func(t *testing.T) {
+		fake := newFakeConsul(map[string]string{
+			"config/app/":          "",
+			"config/app/PORT":      "8080",
+			"config/app/db/host":   "db.local",
+			"config/other/IGNORED": "x",
+		})
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		source, err := FromConsul(ConsulConfig{Address: server.URL, Prefix: "config/app/"})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+
+		keys := source.Keys()
+		if len(keys) != 2 || keys[0] != "PORT" || keys[1] != "db/host" {
+			t.Errorf("expected keys [PORT db/host], got: %v", keys)
+		}
+		val, found, err := source.GetValue("PORT")
+		if err != nil || !found || val != "8080" {
+			t.Errorf("expected 'PORT' to be '8080', got: %q (found=%t, err=%v)", val, found, err)
+		}
+	})
+
+	t.Run("map keys", func(t *testing.T) {
+		fake := newFakeConsul(map[string]string{"config/app/db-host": "db.local"})
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		source, err := FromConsul(ConsulConfig{Address: server.URL, Prefix: "config/app/", KeyFunc: UpperSnakeKey})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		val, found, _ := source.GetValue("DB_HOST")
+		if !found || val != "db.local" {
+			t.Errorf("expected 'DB_HOST' to be 'db.local', got: %q", val)
+		}
+	})
+
+	t.Run("prefix without trailing slash", func(t *testing.T) {
+		fake := newFakeConsul(map[string]string{
+			"config/app/PORT":         "8080",
+			"config/application/HOST": "other",
+		})
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		source, err := FromConsul(ConsulConfig{Address: server.URL, Prefix: "/config/app"})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if keys := source.Keys(); len(keys) != 1 || keys[0] != "PORT" {
+			t.Errorf("expected keys [PORT], got: %v", keys)
+		}
+	})
+
+	t.Run("empty prefix is not an error", func(t *testing.T) {
+		server := httptest.NewServer(newFakeConsul(map[string]string{}))
+		defer server.Close()
+
+		source, err := FromConsul(ConsulConfig{Address: server.URL, Prefix: "config/app/"})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if keys := source.Keys(); len(keys) != 0 {
+			t.Errorf("expected no keys, got: %v", keys)
+		}
+	})
+
+	t.Run("send ACL token", func(t *testing.T) {
+		fake := newFakeConsul(map[string]string{"config/app/PORT": "8080"})
+		fake.token = "acl"
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		if _, err := FromConsul(ConsulConfig{Address: server.URL, Prefix: "config/app/"}); err == nil {
+			t.Error("expected error without token")
+		}
+		if _, err := FromConsul(ConsulConfig{Address: server.URL, Prefix: "config/app/", Token: "acl"}); err != nil {
+			t.Errorf("expected no error with token, got: %s", err)
+		}
+	})
+
+	t.Run("error without address", func(t *testing.T) {
+		source, err := FromConsul(ConsulConfig{Prefix: "config/app/"})
+		if err == nil {
+			t.Error("expected error without address")
+		}
+		if source != nil {
+			t.Error("expected source to be nil on error")
+		}
+	})
+}
+
+func TestConsulSource_Name(t *testing.T) {
+	server := httptest.NewServer(newFakeConsul(map[string]string{}))
+	defer server.Close()
+
+	source, err := FromConsul(ConsulConfig{Address: server.URL + "/", Prefix: "config/app/"})
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+	expectedName := "consul:" + server.URL + "/config/app/"
+	if name := source.Name(); name != expectedName {
+		t.Errorf("expected Name() to return '%s', got: %s", expectedName, name)
+	}
+}
+
+func TestConsulSource_Watch(t *testing.T) {
+	fake := newFakeConsul(map[string]string{"config/app/LEVEL": "info"})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	source, err := FromConsul(ConsulConfig{Address: server.URL, Prefix: "config/app/", WaitTime: time.Second})
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- source.Watch(ctx, func() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+	}()
+
+	fake.set("config/app/LEVEL", "debug")
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected onChange to be called after KV update")
+	}
+	val, _, _ := source.GetValue("LEVEL")
+	if val != "debug" {
+		t.Errorf("expected updated value 'debug', got: %q", val)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled after cancel, got: %v", err)
+	}
+}
+
+func TestConsulSource_WatchWithoutIndex(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		_ = json.NewEncoder(w).Encode([]consulKV{{Key: "config/app/PORT", Value: []byte("8080")}})
+	}))
+	defer server.Close()
+
+	source, err := FromConsul(ConsulConfig{Address: server.URL, Prefix: "config/app/", WaitTime: time.Second})
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+	source.retryInterval = 50 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := source.Watch(ctx, func() {}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context.DeadlineExceeded, got: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests > 10 {
+		t.Errorf("expected watch to back off without an index, got %d requests", requests)
+	}
+}
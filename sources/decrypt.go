@@ -0,0 +1,146 @@
+package sources
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// encryptedPrefix and encryptedSuffix delimit an encrypted value, e.g.
+	// ENC[base64-ciphertext].
+	encryptedPrefix = "ENC["
+	encryptedSuffix = "]"
+)
+
+// Decryptor decrypts the payload of ENC[...] values. The payload is passed
+// exactly as written between the brackets, so implementations choose their
+// own encoding. Implementations for other schemes, such as age, can be
+// plugged into FileOptions.
+type Decryptor interface {
+	// Decrypt returns the plaintext of an encrypted payload.
+	Decrypt(payload string) (string, error)
+}
+
+// AESGCMDecryptor decrypts values encrypted with AES-GCM. The payload is the
+// standard base64 encoding of the 12-byte nonce followed by the ciphertext
+// and tag, as produced by EncryptAESGCM.
+type AESGCMDecryptor struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMDecryptor creates an AES-GCM decryptor from a raw 16, 24, or
+// 32-byte key, selecting AES-128, AES-192, or AES-256.
+// Returns an error if the key has an invalid length.
+func NewAESGCMDecryptor(key []byte) (*AESGCMDecryptor, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCMDecryptor{aead: aead}, nil
+}
+
+// NewAESGCMDecryptorFromFile creates an AES-GCM decryptor from a file holding
+// the base64-encoded key. Surrounding whitespace is ignored.
+// Returns an error if the file cannot be read or the key is invalid.
+func NewAESGCMDecryptorFromFile(path string) (*AESGCMDecryptor, error) {
+	data, err := readFileLimited(path, DefaultMaxFileSize)
+	if err != nil {
+		return nil, fmt.Errorf("error reading key file: %w", err)
+	}
+	key, err := decodeAESKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key in %s: %w", path, err)
+	}
+	return NewAESGCMDecryptor(key)
+}
+
+// NewAESGCMDecryptorFromEnv creates an AES-GCM decryptor from an environment
+// variable holding the base64-encoded key.
+// Returns an error if the variable is unset or the key is invalid.
+func NewAESGCMDecryptorFromEnv(name string) (*AESGCMDecryptor, error) {
+	encoded, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, fmt.Errorf("key environment variable %s is not set", name)
+	}
+	key, err := decodeAESKey(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key in %s: %w", name, err)
+	}
+	return NewAESGCMDecryptor(key)
+}
+
+// Decrypt decrypts and authenticates a base64-encoded nonce and ciphertext.
+func (d *AESGCMDecryptor) Decrypt(payload string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", fmt.Errorf("invalid ciphertext encoding: %w", err)
+	}
+	nonceSize := d.aead.NonceSize()
+	if len(data) < nonceSize+d.aead.Overhead() {
+		return "", errors.New("ciphertext too short")
+	}
+	plaintext, err := d.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", errors.New("decryption failed: wrong key or corrupted ciphertext")
+	}
+	return string(plaintext), nil
+}
+
+// EncryptAESGCM encrypts plaintext with key using AES-GCM and a random nonce,
+// returning a value of the form ENC[...] ready to be written to a
+// configuration file.
+func EncryptAESGCM(key []byte, plaintext string) (string, error) {
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("error generating nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed) + encryptedSuffix, nil
+}
+
+// newAESGCM creates an AES-GCM AEAD for key.
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid aes key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error creating aes-gcm cipher: %w", err)
+	}
+	return aead, nil
+}
+
+// decodeAESKey decodes a base64-encoded key, ignoring surrounding whitespace.
+func decodeAESKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("key is not valid base64: %w", err)
+	}
+	return key, nil
+}
+
+// IsEncrypted reports whether value is an encrypted ENC[...] value.
+func IsEncrypted(value string) bool {
+	_, ok := encryptedPayload(value)
+	return ok
+}
+
+// encryptedPayload reports whether value is an ENC[...] value and returns
+// the payload between the brackets.
+func encryptedPayload(value string) (string, bool) {
+	if !strings.HasPrefix(value, encryptedPrefix) || !strings.HasSuffix(value, encryptedSuffix) {
+		return "", false
+	}
+	return value[len(encryptedPrefix) : len(value)-len(encryptedSuffix)], true
+}
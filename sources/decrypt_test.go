@@ -0,0 +1,143 @@
+package sources
+
+import (
+	"bytes"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAESGCMDecryptor(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+
+	t.Run("round trip", func(t *testing.T) {
+		encrypted, err := EncryptAESGCM(key, "s3cret")
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		payload, ok := encryptedPayload(encrypted)
+		if !ok {
+			t.Fatalf("expected ENC[...] value, got: %s", encrypted)
+		}
+
+		decryptor, err := NewAESGCMDecryptor(key)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		plaintext, err := decryptor.Decrypt(payload)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if plaintext != "s3cret" {
+			t.Errorf("expected 's3cret', got: %q", plaintext)
+		}
+	})
+
+	t.Run("error for wrong key", func(t *testing.T) {
+		encrypted, _ := EncryptAESGCM(key, "s3cret")
+		payload, _ := encryptedPayload(encrypted)
+
+		decryptor, _ := NewAESGCMDecryptor(bytes.Repeat([]byte{2}, 32))
+		if _, err := decryptor.Decrypt(payload); err == nil {
+			t.Error("expected error when decrypting with the wrong key")
+		}
+	})
+
+	t.Run("error for malformed payload", func(t *testing.T) {
+		decryptor, _ := NewAESGCMDecryptor(key)
+		if _, err := decryptor.Decrypt("not base64!"); err == nil {
+			t.Error("expected error for invalid base64")
+		}
+		if _, err := decryptor.Decrypt(base64.StdEncoding.EncodeToString([]byte("short"))); err == nil {
+			t.Error("expected error for short ciphertext")
+		}
+	})
+
+	t.Run("error for invalid key length", func(t *testing.T) {
+		if _, err := NewAESGCMDecryptor([]byte("short")); err == nil {
+			t.Error("expected error for invalid key length")
+		}
+	})
+
+	t.Run("key from file", func(t *testing.T) {
+		keyFile := filepath.Join(t.TempDir(), "config.key")
+		if err := os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+			t.Fatalf("failed to create key file: %s", err)
+		}
+		if _, err := NewAESGCMDecryptorFromFile(keyFile); err != nil {
+			t.Errorf("expected no error, got: %s", err)
+		}
+		if _, err := NewAESGCMDecryptorFromFile(filepath.Join(t.TempDir(), "missing.key")); err == nil {
+			t.Error("expected error for missing key file")
+		}
+	})
+
+	t.Run("key from env", func(t *testing.T) {
+		t.Setenv("CONFIGLY_TEST_KEY", base64.StdEncoding.EncodeToString(key))
+		if _, err := NewAESGCMDecryptorFromEnv("CONFIGLY_TEST_KEY"); err != nil {
+			t.Errorf("expected no error, got: %s", err)
+		}
+
+		t.Setenv("CONFIGLY_TEST_KEY", "not base64!")
+		_, err := NewAESGCMDecryptorFromEnv("CONFIGLY_TEST_KEY")
+		if err == nil || !strings.Contains(err.Error(), "CONFIGLY_TEST_KEY") {
+			t.Errorf("expected error naming the variable, got: %v", err)
+		}
+
+		if _, err := NewAESGCMDecryptorFromEnv("CONFIGLY_TEST_UNSET_KEY"); err == nil {
+			t.Error("expected error for unset variable")
+		}
+	})
+}
+
+func TestFromFileWithOptions(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	decryptor, _ := NewAESGCMDecryptor(key)
+	encrypted, _ := EncryptAESGCM(key, "s3cret")
+
+	writeConfig := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %s", err)
+		}
+		return path
+	}
+
+	t.Run("decrypt ENC values", func(t *testing.T) {
+		path := writeConfig(t, "DB_HOST: localhost\nDB_PASSWORD: "+encrypted+"\n")
+
+		source, err := FromFileWithOptions(path, FileOptions{Decryptor: decryptor})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("DB_PASSWORD"); val != "s3cret" {
+			t.Errorf("expected decrypted value 's3cret', got: %q", val)
+		}
+		if !source.IsSecret("DB_PASSWORD") {
+			t.Error("expected DB_PASSWORD to be secret")
+		}
+		if source.IsSecret("DB_HOST") {
+			t.Error("expected DB_HOST not to be secret")
+		}
+	})
+
+	t.Run("error names key that fails to decrypt", func(t *testing.T) {
+		path := writeConfig(t, "DB_PASSWORD: ENC[bm90IGEgY2lwaGVydGV4dA==]\n")
+
+		_, err := FromFileWithOptions(path, FileOptions{Decryptor: decryptor})
+		if err == nil || !strings.Contains(err.Error(), "DB_PASSWORD") {
+			t.Errorf("expected error naming DB_PASSWORD, got: %v", err)
+		}
+	})
+
+	t.Run("error for encrypted value without decryptor", func(t *testing.T) {
+		path := writeConfig(t, "DB_PASSWORD: "+encrypted+"\n")
+
+		_, err := FromFile(path)
+		if err == nil || !strings.Contains(err.Error(), "DB_PASSWORD") {
+			t.Errorf("expected error naming DB_PASSWORD, got: %v", err)
+		}
+	})
+}
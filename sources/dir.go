@@ -0,0 +1,191 @@
+package sources
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxFileSize is the largest file a DirSource reads when
+	// DirOptions.MaxFileSize is not set.
+	DefaultMaxFileSize int64 = 1 << 20
+	// DefaultPollInterval is how often DirSource.Watch checks for changes when
+	// DirOptions.PollInterval is not set.
+	DefaultPollInterval = 10 * time.Second
+)
+
+// DirOptions configures a DirSource.
+type DirOptions struct {
+	TrimNewline  bool                     // Trim trailing newlines from file contents
+	KeyFunc      func(name string) string // Maps file names to keys (defaults to the file name)
+	MaxFileSize  int64                    // Maximum size of a single file in bytes (defaults to DefaultMaxFileSize)
+	PollInterval time.Duration            // How often Watch checks for changes (defaults to DefaultPollInterval)
+}
+
+// DirSource is a configuration source that reads one value per file from a
+// directory, as used by Docker secrets (/run/secrets) and Kubernetes
+// Secret and ConfigMap volumes. Each file name maps to a key and the file
+// content is the value. Hidden entries, including the "..data" symlinks
+// Kubernetes uses for atomic updates, are skipped.
+type DirSource struct {
+	mu    sync.RWMutex
+	kvMap map[string]string
+	dir   string
+	opts  DirOptions
+}
+
+// FromDir creates a new directory configuration source with trailing
+// newlines trimmed from values and file names used as keys unchanged.
+func FromDir(path string) (*DirSource, error) {
+	return FromDirWithOptions(path, DirOptions{TrimNewline: true})
+}
+
+// FromDirWithOptions creates a new directory configuration source with
+// explicit options. All files are read immediately; an error is returned if
+// the directory cannot be read or any file exceeds the maximum size.
+func FromDirWithOptions(path string, opts DirOptions) (*DirSource, error) {
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = DefaultMaxFileSize
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+
+	s := &DirSource{
+		dir:  path,
+		opts: opts,
+	}
+	kvMap, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	s.kvMap = kvMap
+	return s, nil
+}
+
+// UpperSnakeKey maps a name such as "db-password", "db.password", or
+// "db/password" to an environment-style key such as "DB_PASSWORD". It is
+// intended for use as the KeyFunc of directory and remote sources.
+func UpperSnakeKey(name string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_", "/", "_").Replace(name))
+}
+
+// read loads every regular file in the directory, following symlinks.
+func (s *DirSource) read() (map[string]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+
+	kvMap := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		path := filepath.Join(s.dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", path, err)
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		if info.Size() > s.opts.MaxFileSize {
+			return nil, fmt.Errorf("file %s exceeds maximum size of %d bytes", path, s.opts.MaxFileSize)
+		}
+
+		value, err := readFileLimited(path, s.opts.MaxFileSize)
+		if err != nil {
+			return nil, err
+		}
+		if s.opts.TrimNewline {
+			value = strings.TrimRight(value, "\r\n")
+		}
+		key := name
+		if s.opts.KeyFunc != nil {
+			key = s.opts.KeyFunc(name)
+		}
+		kvMap[key] = value
+	}
+	return kvMap, nil
+}
+
+// readFileLimited reads at most maxSize bytes from path, returning an error
+// if the file is larger (e.g. because it grew after it was stat'ed).
+func readFileLimited(path string, maxSize int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", path, err)
+	}
+	defer f.Close()
+
+	bytes, err := io.ReadAll(io.LimitReader(f, maxSize+1))
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", path, err)
+	}
+	if int64(len(bytes)) > maxSize {
+		return "", fmt.Errorf("file %s exceeds maximum size of %d bytes", path, maxSize)
+	}
+	return string(bytes), nil
+}
+
+// Name returns the name of this source.
+func (s *DirSource) Name() string {
+	return fmt.Sprintf("dir:%s", s.dir)
+}
+
+// GetValue retrieves the content of the file mapped to key.
+func (s *DirSource) GetValue(key string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, found := s.kvMap[key]
+	return val, found, nil
+}
+
+// Keys returns all keys read from the directory, sorted.
+func (s *DirSource) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Sorted(maps.Keys(s.kvMap))
+}
+
+// Watch polls the directory every PollInterval and calls onChange whenever
+// the set of keys or any value has changed. Kubernetes updates mounted
+// volumes by atomically swapping the "..data" symlink, so a poll either sees
+// the old or the new contents. Read errors during a poll, such as a file
+// being replaced mid-read, keep the previous values and are retried on the
+// next poll.
+func (s *DirSource) Watch(ctx context.Context, onChange func()) error {
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			kvMap, err := s.read()
+			if err != nil {
+				continue
+			}
+			s.mu.Lock()
+			changed := !maps.Equal(s.kvMap, kvMap)
+			if changed {
+				s.kvMap = kvMap
+			}
+			s.mu.Unlock()
+			if changed {
+				onChange()
+			}
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeDirFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %s", err)
+	}
+}
+
+// makeKubernetesDir lays out dir the way the kubelet mounts a ConfigMap:
+// the data lives in a timestamped directory, "..data" links to it, and each
+// key is a symlink through "..data".
+func makeKubernetesDir(t *testing.T, dir string, values map[string]string) {
+	t.Helper()
+	dataDir := filepath.Join(dir, "..2024_01_01_00_00_00.000000000")
+	if err := os.Mkdir(dataDir, 0755); err != nil {
+		t.Fatalf("failed to create data dir: %s", err)
+	}
+	for name, value := range values {
+		writeDirFile(t, dataDir, name, value)
+	}
+	if err := os.Symlink(filepath.Base(dataDir), filepath.Join(dir, "..data")); err != nil {
+		t.Fatalf("failed to create ..data link: %s", err)
+	}
+	for name := range values {
+		if err := os.Symlink(filepath.Join("..data", name), filepath.Join(dir, name)); err != nil {
+			t.Fatalf("failed to create key link: %s", err)
+		}
+	}
+}
+
+func TestFromDir(t *testing.T) {
+	t.Run("read one value per file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDirFile(t, dir, "db-password", "s3cret\n")
+		writeDirFile(t, dir, "host", "localhost")
+
+		source, err := FromDir(dir)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+
+		val, found, err := source.GetValue("db-password")
+		if err != nil {
+			t.Errorf("expected no error, got: %s", err)
+		}
+		if !found {
+			t.Error("expected 'db-password' to be found")
+		}
+		if val != "s3cret" {
+			t.Errorf("expected trailing newline to be trimmed, got: %q", val)
+		}
+
+		val, found, _ = source.GetValue("host")
+		if !found || val != "localhost" {
+			t.Errorf("expected 'host' to be 'localhost', got: %q", val)
+		}
+
+		_, found, _ = source.GetValue("missing")
+		if found {
+			t.Error("expected 'missing' not to be found")
+		}
+	})
+
+	t.Run("skip hidden entries and directories", func(t *testing.T) {
+		dir := t.TempDir()
+		makeKubernetesDir(t, dir, map[string]string{"PORT": "8080"})
+		writeDirFile(t, dir, ".hidden", "ignored")
+		if err := os.Mkdir(filepath.Join(dir, "subdir"), 0755); err != nil {
+			t.Fatalf("failed to create subdir: %s", err)
+		}
+
+		source, err := FromDir(dir)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+
+		keys := source.Keys()
+		if len(keys) != 1 || keys[0] != "PORT" {
+			t.Errorf("expected keys [PORT], got: %v", keys)
+		}
+		val, _, _ := source.GetValue("PORT")
+		if val != "8080" {
+			t.Errorf("expected 'PORT' to be '8080', got: %q", val)
+		}
+	})
+
+	t.Run("error when directory does not exist", func(t *testing.T) {
+		source, err := FromDir("/nonexistent/dir")
+		if err == nil {
+			t.Error("expected error for non-existent directory")
+		}
+		if source != nil {
+			t.Error("expected source to be nil on error")
+		}
+	})
+}
+
+func TestFromDirWithOptions(t *testing.T) {
+	t.Run("map names to keys", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDirFile(t, dir, "db-password", "s3cret")
+
+		source, err := FromDirWithOptions(dir, DirOptions{KeyFunc: UpperSnakeKey})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+
+		val, found, _ := source.GetValue("DB_PASSWORD")
+		if !found || val != "s3cret" {
+			t.Errorf("expected 'DB_PASSWORD' to be 's3cret', got: %q", val)
+		}
+	})
+
+	t.Run("keep newlines when not trimming", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDirFile(t, dir, "cert", "line1\nline2\n")
+
+		source, err := FromDirWithOptions(dir, DirOptions{})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+
+		val, _, _ := source.GetValue("cert")
+		if val != "line1\nline2\n" {
+			t.Errorf("expected content to be unchanged, got: %q", val)
+		}
+	})
+
+	t.Run("error when file exceeds maximum size", func(t *testing.T) {
+		dir := t.TempDir()
+		writeDirFile(t, dir, "big", strings.Repeat("x", 11))
+
+		source, err := FromDirWithOptions(dir, DirOptions{MaxFileSize: 10})
+		if err == nil {
+			t.Error("expected error for oversized file")
+		}
+		if source != nil {
+			t.Error("expected source to be nil on error")
+		}
+	})
+}
+
+func TestUpperSnakeKey(t *testing.T) {
+	tests := map[string]string{
+		"db-password": "DB_PASSWORD",
+		"db.host":     "DB_HOST",
+		"db/user":     "DB_USER",
+		"PORT":        "PORT",
+	}
+	for name, expected := range tests {
+		if got := UpperSnakeKey(name); got != expected {
+			t.Errorf("expected UpperSnakeKey(%q) to be '%s', got: %s", name, expected, got)
+		}
+	}
+}
+
+func TestDirSource_Name(t *testing.T) {
+	dir := t.TempDir()
+	source, err := FromDir(dir)
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+
+	expectedName := "dir:" + dir
+	if name := source.Name(); name != expectedName {
+		t.Errorf("expected Name() to return '%s', got: %s", expectedName, name)
+	}
+}
+
+func TestDirSource_Watch(t *testing.T) {
+	dir := t.TempDir()
+	writeDirFile(t, dir, "level", "info")
+
+	source, err := FromDirWithOptions(dir, DirOptions{PollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- source.Watch(ctx, func() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+	}()
+
+	writeDirFile(t, dir, "level", "debug")
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected onChange to be called after file update")
+	}
+
+	val, _, _ := source.GetValue("level")
+	if val != "debug" {
+		t.Errorf("expected updated value 'debug', got: %q", val)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled after cancel, got: %v", err)
+	}
+}
@@ -0,0 +1,360 @@
+package sources
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EtcdConfig contains configuration options for creating an EtcdSource.
+type EtcdConfig struct {
+	Endpoint string // etcd client URL (e.g. http://127.0.0.1:2379)
+	Prefix   string // Key prefix to load (e.g. "/config/app/")
+	Username string // Username for etcd authentication (optional)
+	Password string // Password for etcd authentication (optional)
+	// KeyFunc maps an etcd key, relative to Prefix, to a configly key
+	// (defaults to the relative key unchanged).
+	KeyFunc    func(key string) string
+	HTTPClient *http.Client // HTTP client used for requests (defaults to http.DefaultClient)
+}
+
+// EtcdSource is a configuration source backed by etcd v3, accessed through
+// its JSON gRPC gateway. All keys under the prefix are fetched with a single
+// range request, and Watch streams changes with an etcd watch. When a
+// username is configured, requests rejected as unauthorized re-authenticate
+// and are retried once, so expired tokens are replaced.
+type EtcdSource struct {
+	mu            sync.RWMutex
+	cfg           EtcdConfig
+	client        *http.Client
+	token         string // Guarded by mu
+	kvMap         map[string]string
+	revision      int64
+	retryInterval time.Duration
+}
+
+// etcdKV is a key-value pair as encoded by the etcd JSON gateway.
+// Keys and values are base64 encoded and 64-bit integers are strings.
+type etcdKV struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// etcdAuthPath is the gateway path that exchanges credentials for a token.
+const etcdAuthPath = "/v3/auth/authenticate"
+
+// etcdHeader is the response header carrying the store revision.
+type etcdHeader struct {
+	Revision string `json:"revision"`
+}
+
+// FromEtcd creates a new etcd configuration source and fetches all keys
+// under the configured prefix, authenticating first when a username is set.
+// Returns an error if the endpoint is missing, authentication fails, or the
+// initial fetch fails.
+func FromEtcd(cfg EtcdConfig) (*EtcdSource, error) {
+	if cfg.Endpoint == "" {
+		return nil, errors.New("etcd endpoint is required")
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+
+	s := &EtcdSource{
+		cfg:           cfg,
+		client:        cfg.HTTPClient,
+		retryInterval: defaultWatchRetryInterval,
+	}
+	if s.client == nil {
+		s.client = http.DefaultClient
+	}
+
+	if cfg.Username != "" {
+		if err := s.authenticate(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+	kvMap, revision, err := s.fetch(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	s.kvMap = kvMap
+	s.revision = revision
+	return s, nil
+}
+
+// prefixRangeEnd returns the range_end that selects every key starting with
+// prefix, i.e. prefix with its last byte incremented.
+func prefixRangeEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	// every byte is 0xff: select all keys from prefix onwards
+	return "\x00"
+}
+
+// authenticate exchanges the configured username and password for a token.
+func (s *EtcdSource) authenticate(ctx context.Context) error {
+	var resp struct {
+		Token string `json:"token"`
+	}
+	body := map[string]string{"name": s.cfg.Username, "password": s.cfg.Password}
+	if err := s.post(ctx, etcdAuthPath, body, &resp); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.token = resp.Token
+	s.mu.Unlock()
+	return nil
+}
+
+// fetch reads every key under the prefix in a single range request.
+// Returns the key-value pairs and the store revision they were read at.
+func (s *EtcdSource) fetch(ctx context.Context) (map[string]string, int64, error) {
+	var resp struct {
+		Header etcdHeader `json:"header"`
+		Kvs    []etcdKV   `json:"kvs"`
+	}
+	body := map[string]string{
+		"key":       base64.StdEncoding.EncodeToString([]byte(s.cfg.Prefix)),
+		"range_end": base64.StdEncoding.EncodeToString([]byte(prefixRangeEnd(s.cfg.Prefix))),
+	}
+	if err := s.post(ctx, "/v3/kv/range", body, &resp); err != nil {
+		return nil, 0, err
+	}
+
+	kvMap := make(map[string]string, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		if key, ok := s.mapKey(kv.Key); ok {
+			kvMap[key] = decodeBase64(kv.Value)
+		}
+	}
+	revision, _ := strconv.ParseInt(resp.Header.Revision, 10, 64)
+	return kvMap, revision, nil
+}
+
+// mapKey decodes an etcd key and maps it to a configly key.
+// Returns false for the prefix itself.
+func (s *EtcdSource) mapKey(encoded string) (string, bool) {
+	key := strings.TrimPrefix(decodeBase64(encoded), s.cfg.Prefix)
+	if key == "" {
+		return "", false
+	}
+	if s.cfg.KeyFunc != nil {
+		key = s.cfg.KeyFunc(key)
+	}
+	return key, true
+}
+
+// post sends a JSON request to the etcd gateway and decodes the response into out.
+func (s *EtcdSource) post(ctx context.Context, path string, body, out any) error {
+	res, err := s.request(ctx, path, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding etcd response for %s: %w", path, err)
+	}
+	return nil
+}
+
+// request sends a JSON request to the etcd gateway and returns the response
+// if its status is OK. If the request is unauthorized and a username is
+// configured, it re-authenticates and retries the request once.
+func (s *EtcdSource) request(ctx context.Context, path string, body any) (*http.Response, error) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding etcd request: %w", err)
+	}
+	res, err := s.send(ctx, path, encoded)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusUnauthorized && s.cfg.Username != "" && path != etcdAuthPath {
+		res.Body.Close()
+		if err := s.authenticate(ctx); err != nil {
+			return nil, err
+		}
+		if res, err = s.send(ctx, path, encoded); err != nil {
+			return nil, err
+		}
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("etcd error for %s (status %d)", path, res.StatusCode)
+	}
+	return res, nil
+}
+
+// send posts an encoded JSON request to the etcd gateway with the current
+// token, if any.
+func (s *EtcdSource) send(ctx context.Context, path string, encoded []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.Endpoint+path, bytes.NewReader(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("error creating etcd request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	s.mu.RLock()
+	token := s.token
+	s.mu.RUnlock()
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting %s from etcd: %w", path, err)
+	}
+	return res, nil
+}
+
+// Name returns the name of this source.
+func (s *EtcdSource) Name() string {
+	return fmt.Sprintf("etcd:%s%s", s.cfg.Endpoint, s.cfg.Prefix)
+}
+
+// GetValue retrieves a value by key from the last known state of the prefix.
+func (s *EtcdSource) GetValue(key string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, found := s.kvMap[key]
+	return val, found, nil
+}
+
+// Keys returns all keys under the prefix, sorted.
+func (s *EtcdSource) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Sorted(maps.Keys(s.kvMap))
+}
+
+// Watch opens an etcd watch on the prefix, starting after the last revision
+// read, and calls onChange after each batch of put or delete events has been
+// applied. If etcd cancels the watch, for example because the revision was
+// compacted, the prefix is read again and onChange is called if anything
+// changed. Whenever the watch stream ends, fails, or is cancelled, it is
+// re-established after a short delay. Returns ctx.Err() once ctx is
+// cancelled.
+func (s *EtcdSource) Watch(ctx context.Context, onChange func()) error {
+	for {
+		if err := s.watchOnce(ctx, onChange); err != nil && ctx.Err() == nil {
+			logger.Warn().Err(err).Str("source", s.Name()).Msg("etcd watch failed, retrying")
+		}
+		if ctx.Err() != nil || !sleepContext(ctx, s.retryInterval) {
+			return ctx.Err()
+		}
+	}
+}
+
+// watchOnce runs a single watch stream until it ends, fails, or is cancelled
+// by etcd, in which case the prefix is read again (see resync).
+func (s *EtcdSource) watchOnce(ctx context.Context, onChange func()) error {
+	s.mu.RLock()
+	startRevision := s.revision + 1
+	s.mu.RUnlock()
+
+	body := map[string]any{
+		"create_request": map[string]string{
+			"key":            base64.StdEncoding.EncodeToString([]byte(s.cfg.Prefix)),
+			"range_end":      base64.StdEncoding.EncodeToString([]byte(prefixRangeEnd(s.cfg.Prefix))),
+			"start_revision": strconv.FormatInt(startRevision, 10),
+		},
+	}
+	res, err := s.request(ctx, "/v3/watch", body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 64*1024), int(DefaultMaxFileSize))
+	for scanner.Scan() {
+		var msg struct {
+			Result struct {
+				Header          etcdHeader `json:"header"`
+				Canceled        bool       `json:"canceled"`
+				CompactRevision string     `json:"compact_revision"`
+				Events          []struct {
+					Type string `json:"type"` // "PUT" is the default and may be omitted
+					Kv   etcdKV `json:"kv"`
+				} `json:"events"`
+			} `json:"result"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return fmt.Errorf("error decoding etcd watch response: %w", err)
+		}
+		if msg.Result.Canceled || (msg.Result.CompactRevision != "" && msg.Result.CompactRevision != "0") {
+			return s.resync(ctx, onChange)
+		}
+		if len(msg.Result.Events) == 0 {
+			continue
+		}
+
+		revision, _ := strconv.ParseInt(msg.Result.Header.Revision, 10, 64)
+		s.mu.Lock()
+		kvMap := maps.Clone(s.kvMap)
+		for _, event := range msg.Result.Events {
+			key, ok := s.mapKey(event.Kv.Key)
+			if !ok {
+				continue
+			}
+			if event.Type == "DELETE" {
+				delete(kvMap, key)
+			} else {
+				kvMap[key] = decodeBase64(event.Kv.Value)
+			}
+		}
+		changed := !maps.Equal(s.kvMap, kvMap)
+		s.kvMap = kvMap
+		s.revision = max(s.revision, revision)
+		s.mu.Unlock()
+		if changed {
+			onChange()
+		}
+	}
+	return scanner.Err()
+}
+
+// resync reads the prefix again after etcd cancelled the watch, e.g. because
+// its start revision was compacted, so that events missed by the watch are
+// not lost. It resets the revision the next watch starts from and calls
+// onChange if any value changed.
+func (s *EtcdSource) resync(ctx context.Context, onChange func()) error {
+	kvMap, revision, err := s.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	changed := !maps.Equal(s.kvMap, kvMap)
+	s.kvMap = kvMap
+	s.revision = revision
+	s.mu.Unlock()
+	if changed {
+		onChange()
+	}
+	return nil
+}
+
+// decodeBase64 decodes a base64 string from the etcd JSON gateway,
+// returning an empty string if it is malformed.
+func decodeBase64(encoded string) string {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return ""
+	}
+	return string(decoded)
+}
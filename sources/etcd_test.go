@@ -0,0 +1,313 @@
+package sources
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEtcd is an in-process stand-in for the etcd v3 JSON gateway,
+// supporting range requests, streaming watches, and authentication.
+type fakeEtcd struct {
+	mu       sync.Mutex
+	kv       map[string]string
+	revision int64
+	events   chan map[string]any // watch events pushed to the open stream
+	password string
+	tokens   int // number of tokens issued; only the latest is valid
+}
+
+func newFakeEtcd(kv map[string]string) *fakeEtcd {
+	return &fakeEtcd{kv: kv, revision: 5, events: make(chan map[string]any, 10)}
+}
+
+func (f *fakeEtcd) put(key, value string) {
+	f.mu.Lock()
+	f.kv[key] = value
+	f.revision++
+	revision := f.revision
+	f.mu.Unlock()
+	f.events <- map[string]any{
+		"header": map[string]string{"revision": strconv.FormatInt(revision, 10)},
+		"events": []map[string]any{{"kv": etcdKV{Key: encode(key), Value: encode(value)}}},
+	}
+}
+
+func (f *fakeEtcd) delete(key string) {
+	f.mu.Lock()
+	delete(f.kv, key)
+	f.revision++
+	revision := f.revision
+	f.mu.Unlock()
+	f.events <- map[string]any{
+		"header": map[string]string{"revision": strconv.FormatInt(revision, 10)},
+		"events": []map[string]any{{"type": "DELETE", "kv": etcdKV{Key: encode(key)}}},
+	}
+}
+
+// compact changes key without a watch event and cancels the open watch as
+// etcd does when the watch's start revision has been compacted.
+func (f *fakeEtcd) compact(key, value string) {
+	f.mu.Lock()
+	f.kv[key] = value
+	f.revision++
+	revision := f.revision
+	f.mu.Unlock()
+	f.events <- map[string]any{
+		"header":           map[string]string{"revision": strconv.FormatInt(revision, 10)},
+		"canceled":         true,
+		"compact_revision": strconv.FormatInt(revision-1, 10),
+	}
+}
+
+// expireToken invalidates the latest token issued.
+func (f *fakeEtcd) expireToken() {
+	f.mu.Lock()
+	f.tokens++
+	f.mu.Unlock()
+}
+
+func (f *fakeEtcd) token() string {
+	return "etcd-token-" + strconv.Itoa(f.tokens)
+}
+
+func encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	f.mu.Lock()
+	token := f.token()
+	f.mu.Unlock()
+	if f.password != "" && r.URL.Path != "/v3/auth/authenticate" && r.Header.Get("Authorization") != token {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v3/auth/authenticate":
+		if body["password"] != f.password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		f.mu.Lock()
+		f.tokens++
+		token := f.token()
+		f.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": token})
+	case "/v3/kv/range":
+		key, _ := base64.StdEncoding.DecodeString(body["key"].(string))
+		f.mu.Lock()
+		var kvs []etcdKV
+		for k, v := range f.kv {
+			if strings.HasPrefix(k, string(key)) {
+				kvs = append(kvs, etcdKV{Key: encode(k), Value: encode(v)})
+			}
+		}
+		resp := map[string]any{
+			"header": map[string]string{"revision": strconv.FormatInt(f.revision, 10)},
+			"kvs":    kvs,
+		}
+		f.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(resp)
+	case "/v3/watch":
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case result := <-f.events:
+				_ = json.NewEncoder(w).Encode(map[string]any{"result": result})
+				w.(http.Flusher).Flush()
+				if result["canceled"] == true {
+					return
+				}
+			}
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestFromEtcd(t *testing.T) {
+	t.Run("fetch prefix in one request", func(t *testing.T) {
+		fake := newFakeEtcd(map[string]string{
+			"/config/app/PORT":  "8080",
+			"/config/app/HOST":  "localhost",
+			"/config/other/KEY": "ignored",
+		})
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		source, err := FromEtcd(EtcdConfig{Endpoint: server.URL, Prefix: "/config/app/"})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+
+		keys := source.Keys()
+		if len(keys) != 2 || keys[0] != "HOST" || keys[1] != "PORT" {
+			t.Errorf("expected keys [HOST PORT], got: %v", keys)
+		}
+		val, found, err := source.GetValue("PORT")
+		if err != nil || !found || val != "8080" {
+			t.Errorf("expected 'PORT' to be '8080', got: %q (found=%t, err=%v)", val, found, err)
+		}
+	})
+
+	t.Run("authenticate", func(t *testing.T) {
+		fake := newFakeEtcd(map[string]string{"/config/app/PORT": "8080"})
+		fake.password = "pass"
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		if _, err := FromEtcd(EtcdConfig{Endpoint: server.URL, Prefix: "/config/app/", Username: "root", Password: "wrong"}); err == nil {
+			t.Error("expected error with wrong password")
+		}
+		source, err := FromEtcd(EtcdConfig{Endpoint: server.URL, Prefix: "/config/app/", Username: "root", Password: "pass"})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("PORT"); val != "8080" {
+			t.Errorf("expected 'PORT' to be '8080', got: %q", val)
+		}
+	})
+
+	t.Run("error without endpoint", func(t *testing.T) {
+		source, err := FromEtcd(EtcdConfig{Prefix: "/config/app/"})
+		if err == nil {
+			t.Error("expected error without endpoint")
+		}
+		if source != nil {
+			t.Error("expected source to be nil on error")
+		}
+	})
+}
+
+func TestPrefixRangeEnd(t *testing.T) {
+	tests := map[string]string{
+		"/config/app/": "/config/app0",
+		"a":            "b",
+		"a\xff":        "b",
+	}
+	for prefix, expected := range tests {
+		if got := prefixRangeEnd(prefix); got != expected {
+			t.Errorf("expected prefixRangeEnd(%q) to be %q, got: %q", prefix, expected, got)
+		}
+	}
+}
+
+func TestEtcdSource_Name(t *testing.T) {
+	server := httptest.NewServer(newFakeEtcd(map[string]string{}))
+	defer server.Close()
+
+	source, err := FromEtcd(EtcdConfig{Endpoint: server.URL, Prefix: "/config/app/"})
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+	expectedName := "etcd:" + server.URL + "/config/app/"
+	if name := source.Name(); name != expectedName {
+		t.Errorf("expected Name() to return '%s', got: %s", expectedName, name)
+	}
+}
+
+func TestEtcdSource_Watch(t *testing.T) {
+	fake := newFakeEtcd(map[string]string{"/config/app/LEVEL": "info", "/config/app/OLD": "x"})
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	source, err := FromEtcd(EtcdConfig{Endpoint: server.URL, Prefix: "/config/app/"})
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := make(chan struct{}, 2)
+	done := make(chan error, 1)
+	go func() {
+		done <- source.Watch(ctx, func() { changed <- struct{}{} })
+	}()
+
+	waitChange := func() {
+		t.Helper()
+		select {
+		case <-changed:
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected onChange to be called")
+		}
+	}
+
+	fake.put("/config/app/LEVEL", "debug")
+	waitChange()
+	if val, _, _ := source.GetValue("LEVEL"); val != "debug" {
+		t.Errorf("expected updated value 'debug', got: %q", val)
+	}
+
+	fake.delete("/config/app/OLD")
+	waitChange()
+	if _, found, _ := source.GetValue("OLD"); found {
+		t.Error("expected deleted key not to be found")
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled after cancel, got: %v", err)
+	}
+}
+
+func TestEtcdSource_WatchCompaction(t *testing.T) {
+	fake := newFakeEtcd(map[string]string{"/config/app/LEVEL": "info"})
+	fake.password = "pass"
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	source, err := FromEtcd(EtcdConfig{Endpoint: server.URL, Prefix: "/config/app/", Username: "root", Password: "pass"})
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+	source.retryInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changed := make(chan struct{}, 2)
+	go func() {
+		_ = source.Watch(ctx, func() { changed <- struct{}{} })
+	}()
+
+	fake.expireToken()
+	fake.compact("/config/app/LEVEL", "debug")
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected onChange to be called after compaction")
+	}
+	if val, _, _ := source.GetValue("LEVEL"); val != "debug" {
+		t.Errorf("expected re-read value 'debug', got: %q", val)
+	}
+	source.mu.RLock()
+	revision := source.revision
+	source.mu.RUnlock()
+	if revision != 6 {
+		t.Errorf("expected revision to be reset to 6, got: %d", revision)
+	}
+
+	fake.put("/config/app/LEVEL", "warn")
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected watch to be re-established after compaction")
+	}
+	if val, _, _ := source.GetValue("LEVEL"); val != "warn" {
+		t.Errorf("expected updated value 'warn', got: %q", val)
+	}
+}
@@ -1,9 +1,12 @@
 package sources
 
 import (
-	"encoding/json"
+	"bytes"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
+	"maps"
 	"os"
 	"slices"
 	"strings"
@@ -13,95 +16,344 @@ import (
 )
 
 type FileSource struct {
-	kvMap    map[string]string
-	filePath string
+	kvMap   map[string]string
+	secrets map[string]bool // Keys whose values were decrypted from ENC[...]
+	kind    string          // Kind of location, e.g. "file" or "fs"
+	path    string          // Location the document was read from
 }
 
-func FromFile(path string) (*FileSource, error) {
-	bytes, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("error reading file: %w", err)
+// FileOptions configures a FileSource.
+type FileOptions struct {
+	// Decryptor decrypts values of the form ENC[...]. Decrypted values are
+	// reported as secrets (see SecretMarker). When nil, encrypted values
+	// cause an error.
+	Decryptor Decryptor
+	// Documents selects which documents of a multi-document YAML file are
+	// used (see DocumentIndex). Selected documents are deep-merged in order,
+	// later documents overriding earlier ones. When nil, all documents are
+	// merged.
+	Documents DocumentSelector
+}
+
+// DocumentSelector reports whether the document at index (0-based) of a
+// multi-document YAML file should be used.
+type DocumentSelector func(index int, doc map[string]any) bool
+
+// DocumentIndex returns a DocumentSelector that selects only the document at
+// index.
+func DocumentIndex(index int) DocumentSelector {
+	return func(i int, _ map[string]any) bool {
+		return i == index
 	}
+}
+
+// Format identifies the syntax of a configuration document.
+type Format string
+
+const (
+	FormatJSON  Format = "json"  // JSON documents (.json)
+	FormatJSONC Format = "jsonc" // JSON with comments and trailing commas (.jsonc)
+	FormatJSON5 Format = "json5" // JSON5 documents (.json5)
+	FormatYAML  Format = "yaml"  // YAML documents, possibly multi-document (.yaml, .yml)
+	FormatEnv   Format = "env"   // dotenv documents (.env, .env.local, config.env)
+)
+
+// DetectFormat determines the document format of path from its extension.
+// A file is treated as a dotenv file when its extension is "env" or when
+// "env" appears as an inner extension (e.g. .env.local).
+// Returns an error if the path has no extension or the extension is unsupported.
+func DetectFormat(path string) (Format, error) {
 	split := strings.Split(path, ".")
 	if len(split) < 2 || split[len(split)-1] == "" {
-		return nil, fmt.Errorf("file has no extension: %s", path)
+		return "", fmt.Errorf("file has no extension: %s", path)
 	}
 
-	switch split[len(split)-1] {
+	ext := split[len(split)-1]
+	switch ext {
 	case "json":
-		kvMap, err := unmarshalFile(bytes, "json", json.Unmarshal)
-		if err != nil {
-			return nil, err
-		}
-		return &FileSource{
-			kvMap:    kvMap,
-			filePath: path,
-		}, nil
+		return FormatJSON, nil
+	case "jsonc":
+		return FormatJSONC, nil
+	case "json5":
+		return FormatJSON5, nil
 	case "yml", "yaml":
-		kvMap, err := unmarshalFile(bytes, "yaml", yaml.Unmarshal)
+		return FormatYAML, nil
+	}
+
+	// Check if this is an env file: extension is "env" OR "env" appears in the middle
+	// Examples: .env, .env.local, config.env
+	if ext == "env" || slices.Contains(split[1:len(split)-1], "env") {
+		return FormatEnv, nil
+	}
+	return "", errors.New("unsupported file type")
+}
+
+func FromFile(path string) (*FileSource, error) {
+	return FromFileWithOptions(path, FileOptions{})
+}
+
+// FromFileWithOptions creates a new file configuration source with the given
+// options. Values of the form ENC[...] are decrypted with opts.Decryptor.
+// Nested objects are flattened into keys joined by NestedKeySeparator.
+// Returns an error if the file cannot be read or parsed, if a key is set both
+// directly and through a nested object, or naming each key whose value cannot
+// be decrypted.
+func FromFileWithOptions(path string, opts FileOptions) (*FileSource, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	return newDocumentSource("file", path, bytes, "", opts)
+}
+
+// FromFS creates a new configuration source from the file at path in fsys,
+// such as an embed.FS holding default configuration. The format is detected
+// from the path's extension, as for FromFile.
+func FromFS(fsys fs.FS, path string) (*FileSource, error) {
+	return FromFSWithOptions(fsys, path, FileOptions{})
+}
+
+// FromFSWithOptions creates a new configuration source from the file at path
+// in fsys with the given options.
+func FromFSWithOptions(fsys fs.FS, path string, opts FileOptions) (*FileSource, error) {
+	bytes, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	return newDocumentSource("fs", path, bytes, "", opts)
+}
+
+// FromReader creates a new configuration source from a document read from r,
+// such as os.Stdin. The source is named "reader:<name>". When format is
+// empty it is detected from name's extension, as for FromFile.
+func FromReader(r io.Reader, format Format, name string) (*FileSource, error) {
+	return FromReaderWithOptions(r, format, name, FileOptions{})
+}
+
+// FromReaderWithOptions creates a new configuration source from a document
+// read from r with the given options.
+func FromReaderWithOptions(r io.Reader, format Format, name string, opts FileOptions) (*FileSource, error) {
+	bytes, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", name, err)
+	}
+	return newDocumentSource("reader", name, bytes, format, opts)
+}
+
+// newDocumentSource parses a document read from path and creates a source
+// for it. When format is empty it is detected from path.
+func newDocumentSource(kind, path string, bytes []byte, format Format, opts FileOptions) (*FileSource, error) {
+	if format == "" {
+		detected, err := DetectFormat(path)
 		if err != nil {
 			return nil, err
 		}
-		return &FileSource{
-			kvMap:    kvMap,
-			filePath: path,
-		}, nil
+		format = detected
 	}
 
-	// Check if this is an env file: extension is "env" OR "env" appears in the middle
-	// Examples: .env, .env.local, config.env
-	ext := split[len(split)-1]
-	isEnvFile := ext == "env" || slices.Contains(split[1:len(split)-1], "env")
-	if !isEnvFile {
-		return nil, errors.New("unsupported file type")
+	kvMap, err := parseDocument(bytes, format, opts.Documents)
+	if err != nil {
+		return nil, err
 	}
+	return newFileSource(kind, path, kvMap, opts)
+}
 
-	kvMap, err := godotenv.Read(path)
+// newFileSource creates a FileSource for the values parsed from path,
+// decrypting ENC[...] values with opts.Decryptor.
+func newFileSource(kind, path string, kvMap map[string]string, opts FileOptions) (*FileSource, error) {
+	secrets, err := decryptValues(kvMap, opts.Decryptor)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing env file: %w", err)
+		return nil, fmt.Errorf("error decrypting %s: %w", path, err)
 	}
 
 	return &FileSource{
-		kvMap:    kvMap,
-		filePath: path,
+		kvMap:   kvMap,
+		secrets: secrets,
+		kind:    kind,
+		path:    path,
 	}, nil
 }
 
-func unmarshalFile(bytes []byte, fileType string, unmarshalFunc func(bytes []byte, out any) error) (map[string]string, error) {
-	var out map[string]interface{}
+// decryptValues replaces every ENC[...] value in kvMap with its plaintext
+// and returns the set of decrypted keys. Failures are reported for all keys
+// at once, in key order.
+func decryptValues(kvMap map[string]string, decryptor Decryptor) (map[string]bool, error) {
+	secrets := make(map[string]bool)
+	var decryptErrors []error
+	for _, key := range slices.Sorted(maps.Keys(kvMap)) {
+		payload, ok := encryptedPayload(kvMap[key])
+		if !ok {
+			continue
+		}
+		if decryptor == nil {
+			decryptErrors = append(decryptErrors, fmt.Errorf("%s: encrypted value but no decryptor configured", key))
+			continue
+		}
+		plaintext, err := decryptor.Decrypt(payload)
+		if err != nil {
+			decryptErrors = append(decryptErrors, fmt.Errorf("%s: %w", key, err))
+			continue
+		}
+		kvMap[key] = plaintext
+		secrets[key] = true
+	}
+	return secrets, errors.Join(decryptErrors...)
+}
+
+// NestedKeySeparator joins the keys of nested objects in documents, so
+// DB: {HOST: a} is loaded as DB_HOST, the key of a HOST field in a nested
+// struct tagged DB.
+const NestedKeySeparator = "_"
+
+// parseDocument parses a configuration document in the given format into
+// key-value pairs, flattening nested objects (see flattenDocument).
+func parseDocument(bytes []byte, format Format, selector DocumentSelector) (map[string]string, error) {
+	doc, err := decodeDocument(bytes, format, selector)
+	if err != nil {
+		return nil, err
+	}
+	return flattenDocument(doc)
+}
+
+// decodeDocument decodes a configuration document in the given format,
+// keeping nested objects and arrays. The documents of a multi-document YAML
+// file chosen by selector (all when nil) are deep-merged in order.
+func decodeDocument(bytes []byte, format Format, selector DocumentSelector) (map[string]any, error) {
+	switch format {
+	case FormatJSON:
+		return unmarshalFile(bytes, "json", decodeJSON)
+	case FormatJSONC, FormatJSON5:
+		return unmarshalFile(bytes, string(format), decodeJSON5)
+	case FormatYAML:
+		return decodeYAMLDocuments(bytes, selector)
+	case FormatEnv:
+		kvMap, err := godotenv.UnmarshalBytes(bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing env file: %w", err)
+		}
+		doc := make(map[string]any, len(kvMap))
+		for key, value := range kvMap {
+			doc[key] = value
+		}
+		return doc, nil
+	default:
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+}
+
+func unmarshalFile(bytes []byte, fileType string, unmarshalFunc func(bytes []byte, out any) error) (map[string]any, error) {
+	var out map[string]any
 	err := unmarshalFunc(bytes, &out)
 	if err != nil {
 		return nil, fmt.Errorf("error parsing %s file: %w", fileType, err)
 	}
+	if out == nil {
+		out = make(map[string]any)
+	}
+	return out, nil
+}
 
-	// Convert to map[string]string, filtering out non-scalar values
-	result := make(map[string]string)
-	for key, value := range out {
-		// Only include scalar values (strings, numbers, booleans)
-		// Objects, arrays, and null are ignored
-		switch v := value.(type) {
-		case string:
-			result[key] = v
-		case bool:
-			result[key] = fmt.Sprintf("%t", v)
-		case float64: // JSON numbers are float64
-			result[key] = fmt.Sprintf("%v", v)
-		case int, int8, int16, int32, int64:
-			result[key] = fmt.Sprintf("%d", v)
-		case uint, uint8, uint16, uint32, uint64:
-			result[key] = fmt.Sprintf("%d", v)
-			// Ignore: maps, slices, nil (objects, arrays, null)
+// decodeYAMLDocuments decodes every document of a YAML stream and deep-merges
+// those chosen by selector (all when nil) in order. Empty documents are
+// skipped but still counted for selection.
+func decodeYAMLDocuments(data []byte, selector DocumentSelector) (map[string]any, error) {
+	merged := make(map[string]any)
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for index := 0; ; index++ {
+		var node yaml.Node
+		if err := dec.Decode(&node); errors.Is(err, io.EOF) {
+			return merged, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("error parsing yaml file: %w", err)
 		}
+
+		var doc map[string]any
+		if err := node.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("error parsing yaml file: document %d: %w", index, err)
+		}
+		if doc == nil || (selector != nil && !selector(index, doc)) {
+			continue
+		}
+		mergeDocuments(merged, doc)
 	}
+}
 
+// flattenDocument converts a decoded document to map[string]string. Scalar
+// values are kept under their key and the values of nested objects under
+// their path joined with NestedKeySeparator; arrays and nulls are skipped.
+// Returns an error if two values flatten to the same key, e.g. DB_HOST and
+// DB: {HOST: ...}.
+func flattenDocument(doc map[string]any) (map[string]string, error) {
+	result := make(map[string]string)
+	paths := make(map[string]string) // Document path of each key, for collisions
+	var collisions []error
+	var flatten func(doc map[string]any, keyPrefix, pathPrefix string)
+	flatten = func(doc map[string]any, keyPrefix, pathPrefix string) {
+		for _, key := range slices.Sorted(maps.Keys(doc)) {
+			flatKey, path := keyPrefix+key, pathPrefix+key
+			if nested, ok := doc[key].(map[string]any); ok {
+				flatten(nested, flatKey+NestedKeySeparator, path+".")
+				continue
+			}
+			str, ok := ScalarString(doc[key])
+			if !ok {
+				continue
+			}
+			if other, exists := paths[flatKey]; exists {
+				collisions = append(collisions, fmt.Errorf("key %s is set by both %s and %s", flatKey, other, path))
+				continue
+			}
+			result[flatKey], paths[flatKey] = str, path
+		}
+	}
+	flatten(doc, "", "")
+	if len(collisions) > 0 {
+		return nil, errors.Join(collisions...)
+	}
 	return result, nil
 }
 
+// ScalarString converts a decoded scalar value (string, number, or boolean)
+// to its string form. Returns false for objects, arrays, and null.
+func ScalarString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case bool:
+		return fmt.Sprintf("%t", v), true
+	case float64: // JSON numbers are float64
+		return fmt.Sprintf("%v", v), true
+	case int, int8, int16, int32, int64:
+		return fmt.Sprintf("%d", v), true
+	case uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v), true
+	default:
+		// Ignore: maps, slices, nil (objects, arrays, null)
+		return "", false
+	}
+}
+
 func (fs *FileSource) Name() string {
-	return fmt.Sprintf("file:%s", fs.filePath)
+	return fmt.Sprintf("%s:%s", fs.kind, fs.path)
 }
 
 func (fs *FileSource) GetValue(key string) (string, bool, error) {
 	val, found := fs.kvMap[key]
 	return val, found, nil
 }
+
+// IsSecret reports whether the value of key was decrypted from ENC[...].
+func (fs *FileSource) IsSecret(key string) bool {
+	return fs.secrets[key]
+}
+
+// Keys returns all scalar keys read from the file, sorted.
+func (fs *FileSource) Keys() []string {
+	return slices.Sorted(maps.Keys(fs.kvMap))
+}
+
+// StrictKeys returns all scalar keys read from the file, sorted, since every
+// key of a configuration file is meant for the loader.
+func (fs *FileSource) StrictKeys() []string {
+	return fs.Keys()
+}
@@ -3,6 +3,7 @@ package sources
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -216,6 +217,122 @@ servers:
 		}
 	})
 
+	t.Run("flatten nested objects", func(t *testing.T) {
+		content := `{"DB": {"HOST": "db.local", "POOL": {"MAX": 10}}, "HOSTS": ["a"], "EMPTY": null}`
+		source, err := FromReader(strings.NewReader(content), FormatJSON, "nested")
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("DB_HOST"); val != "db.local" {
+			t.Errorf("expected 'DB_HOST' to be 'db.local', got: %q", val)
+		}
+		if val, _, _ := source.GetValue("DB_POOL_MAX"); val != "10" {
+			t.Errorf("expected 'DB_POOL_MAX' to be '10', got: %q", val)
+		}
+		if keys := source.Keys(); len(keys) != 2 {
+			t.Errorf("expected keys [DB_HOST DB_POOL_MAX], got: %v", keys)
+		}
+	})
+
+	t.Run("error for key set both flat and nested", func(t *testing.T) {
+		content := "DB_HOST: flat\nDB:\n  HOST: nested\n"
+		_, err := FromReader(strings.NewReader(content), FormatYAML, "collision")
+		if err == nil || !strings.Contains(err.Error(), "key DB_HOST is set by both DB.HOST and DB_HOST") {
+			t.Errorf("expected collision error, got: %v", err)
+		}
+	})
+
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected Format
+		wantErr  bool
+	}{
+		{path: "config.json", expected: FormatJSON},
+		{path: "config.jsonc", expected: FormatJSONC},
+		{path: "config.json5", expected: FormatJSON5},
+		{path: "config.yaml", expected: FormatYAML},
+		{path: "config.yml", expected: FormatYAML},
+		{path: ".env", expected: FormatEnv},
+		{path: ".env.local", expected: FormatEnv},
+		{path: "config.env", expected: FormatEnv},
+		{path: "config.txt", wantErr: true},
+		{path: "config", wantErr: true},
+		{path: "config.", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			format, err := DetectFormat(tt.path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error for %s", tt.path)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("expected no error, got: %s", err)
+			}
+			if format != tt.expected {
+				t.Errorf("expected format '%s', got: %s", tt.expected, format)
+			}
+		})
+	}
+}
+
+func TestFromFile_MultiDocumentYAML(t *testing.T) {
+	content := `HOST: localhost
+PORT: 8080
+---
+---
+HOST: staging.example.com
+---
+HOST: prod.example.com
+DEBUG: false
+`
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %s", err)
+	}
+
+	t.Run("merge documents in order", func(t *testing.T) {
+		source, err := FromFile(path)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		expected := map[string]string{"HOST": "prod.example.com", "PORT": "8080", "DEBUG": "false"}
+		for key, want := range expected {
+			if val, _, _ := source.GetValue(key); val != want {
+				t.Errorf("expected '%s' to be '%s', got: %q", key, want, val)
+			}
+		}
+	})
+
+	t.Run("select a document", func(t *testing.T) {
+		source, err := FromFileWithOptions(path, FileOptions{Documents: DocumentIndex(2)})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("HOST"); val != "staging.example.com" {
+			t.Errorf("expected 'HOST' to be 'staging.example.com', got: %q", val)
+		}
+		if _, found, _ := source.GetValue("PORT"); found {
+			t.Error("expected 'PORT' from an unselected document not to be found")
+		}
+	})
+
+	t.Run("error names the failing document", func(t *testing.T) {
+		invalid := filepath.Join(t.TempDir(), "invalid.yaml")
+		if err := os.WriteFile(invalid, []byte("HOST: localhost\n---\n- a list\n"), 0644); err != nil {
+			t.Fatalf("failed to create test file: %s", err)
+		}
+		_, err := FromFile(invalid)
+		if err == nil || !strings.Contains(err.Error(), "document 1") {
+			t.Errorf("expected error naming document 1, got: %v", err)
+		}
+	})
 }
 
 func TestFileSource_Name(t *testing.T) {
@@ -242,6 +359,30 @@ func TestFileSource_Name(t *testing.T) {
 	}
 }
 
+func TestFileSource_Keys(t *testing.T) {
+	tmpDir := t.TempDir()
+	yamlFile := filepath.Join(tmpDir, "config.yaml")
+	content := `PORT: 8080
+HOST: localhost
+DB:
+  HOST: db.local
+servers:
+  - server1`
+	if err := os.WriteFile(yamlFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %s", err)
+	}
+
+	source, err := FromFile(yamlFile)
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+
+	keys := source.Keys()
+	if len(keys) != 3 || keys[0] != "DB_HOST" || keys[1] != "HOST" || keys[2] != "PORT" {
+		t.Errorf("expected keys [DB_HOST HOST PORT], got: %v", keys)
+	}
+}
+
 func TestFileSource_GetValue_JSON(t *testing.T) {
 	tmpDir := t.TempDir()
 	jsonFile := filepath.Join(tmpDir, "config.json")
@@ -0,0 +1,280 @@
+package sources
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// normalizedJSON is a JSONC or JSON5 document rewritten as standard JSON.
+// Line breaks are preserved, so line numbers match the original document;
+// offsets maps each byte of data to its offset in the original.
+type normalizedJSON struct {
+	data    []byte
+	offsets []int
+}
+
+// write appends b, taken from offset off in the original document.
+func (n *normalizedJSON) write(off int, b ...byte) {
+	n.data = append(n.data, b...)
+	for range b {
+		n.offsets = append(n.offsets, off)
+	}
+}
+
+// original maps an offset in the normalized document to the original one.
+func (n *normalizedJSON) original(offset int64) int64 {
+	if len(n.offsets) == 0 {
+		return 0
+	}
+	if offset >= int64(len(n.offsets)) {
+		return int64(n.offsets[len(n.offsets)-1]) + 1
+	}
+	return int64(n.offsets[offset])
+}
+
+// NormalizeJSON5 rewrites a JSONC or JSON5 document as standard JSON,
+// preserving line breaks so that line numbers in the result match the
+// original. See normalizeJSON5 for the supported syntax.
+func NormalizeJSON5(src []byte) ([]byte, error) {
+	normalized, err := normalizeJSON5(src)
+	if err != nil {
+		return nil, err
+	}
+	return normalized.data, nil
+}
+
+// normalizeJSON5 rewrites a JSONC or JSON5 document as standard JSON. It
+// supports // and /* */ comments, trailing commas, unquoted object keys,
+// single-quoted strings, hexadecimal integers, explicit plus signs, and
+// leading or trailing decimal points. Infinity and NaN are rejected since
+// they have no JSON representation.
+// Returns an error with the line and column of malformed input.
+func normalizeJSON5(src []byte) (*normalizedJSON, error) {
+	n := &normalizedJSON{data: make([]byte, 0, len(src)), offsets: make([]int, 0, len(src))}
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '/' && i+1 < len(src) && (src[i+1] == '/' || src[i+1] == '*'):
+			end, err := skipComment(src, i)
+			if err != nil {
+				return nil, err
+			}
+			// keep line breaks so that line numbers are unchanged
+			for j := i; j < end; j++ {
+				if src[j] == '\n' {
+					n.write(j, '\n')
+				}
+			}
+			i = end
+		case c == '"' || c == '\'':
+			end, err := n.writeString(src, i)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case c == ',':
+			if next := skipSpaceAndComments(src, i+1); next < len(src) && (src[next] == '}' || src[next] == ']') {
+				i++ // drop trailing comma
+				continue
+			}
+			n.write(i, c)
+			i++
+		case isIdentStart(c):
+			end := i + 1
+			for end < len(src) && isIdentPart(src[end]) {
+				end++
+			}
+			ident := string(src[i:end])
+			switch ident {
+			case "true", "false", "null":
+				n.write(i, src[i:end]...)
+			case "Infinity", "NaN":
+				return nil, positionError(src, int64(i), fmt.Errorf("%s is not supported", ident))
+			default:
+				if next := skipSpaceAndComments(src, end); next >= len(src) || src[next] != ':' {
+					return nil, positionError(src, int64(i), fmt.Errorf("unexpected identifier %s", ident))
+				}
+				n.write(i, '"')
+				n.write(i, src[i:end]...)
+				n.write(end-1, '"')
+			}
+			i = end
+		case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(src) && isNumberPart(src[end], src[end-1]) {
+				end++
+			}
+			number, err := normalizeNumber(string(src[i:end]))
+			if err != nil {
+				return nil, positionError(src, int64(i), err)
+			}
+			n.write(i, []byte(number)...)
+			i = end
+		default:
+			n.write(i, c)
+			i++
+		}
+	}
+	return n, nil
+}
+
+// skipComment returns the offset just past the comment starting at i.
+// Line comments end before the line break.
+func skipComment(src []byte, i int) (int, error) {
+	if src[i+1] == '/' {
+		end := bytes.IndexByte(src[i:], '\n')
+		if end < 0 {
+			return len(src), nil
+		}
+		return i + end, nil
+	}
+	end := bytes.Index(src[i+2:], []byte("*/"))
+	if end < 0 {
+		return 0, positionError(src, int64(i), errors.New("unterminated comment"))
+	}
+	return i + 2 + end + 2, nil
+}
+
+// skipSpaceAndComments returns the offset of the next byte at or after i that
+// is neither whitespace nor part of a comment.
+func skipSpaceAndComments(src []byte, i int) int {
+	for i < len(src) {
+		switch {
+		case src[i] == ' ' || src[i] == '\t' || src[i] == '\n' || src[i] == '\r':
+			i++
+		case src[i] == '/' && i+1 < len(src) && (src[i+1] == '/' || src[i+1] == '*'):
+			end, err := skipComment(src, i)
+			if err != nil {
+				return len(src)
+			}
+			i = end
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// writeString writes the string literal starting at i as a double-quoted
+// JSON string and returns the offset just past it.
+func (n *normalizedJSON) writeString(src []byte, i int) (int, error) {
+	quote := src[i]
+	n.write(i, '"')
+	for j := i + 1; j < len(src); j++ {
+		c := src[j]
+		switch {
+		case c == quote:
+			n.write(j, '"')
+			return j + 1, nil
+		case c == '\\' && j+1 < len(src):
+			if src[j+1] == '\'' {
+				n.write(j, '\'') // \' is not a valid JSON escape
+			} else {
+				n.write(j, c, src[j+1])
+			}
+			j++
+		case c == '"':
+			n.write(j, '\\', '"') // only reachable in single-quoted strings
+		case c == '\n':
+			return 0, positionError(src, int64(j), errors.New("unterminated string"))
+		default:
+			n.write(j, c)
+		}
+	}
+	return 0, positionError(src, int64(i), errors.New("unterminated string"))
+}
+
+// normalizeNumber rewrites a JSON5 number as a JSON number.
+func normalizeNumber(number string) (string, error) {
+	number = strings.TrimPrefix(number, "+")
+	unsigned := strings.TrimPrefix(number, "-")
+	sign := number[:len(number)-len(unsigned)]
+	if strings.HasPrefix(unsigned, "0x") || strings.HasPrefix(unsigned, "0X") {
+		val, err := strconv.ParseUint(unsigned[2:], 16, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid hexadecimal number %s", number)
+		}
+		return sign + strconv.FormatUint(val, 10), nil
+	}
+	if strings.HasPrefix(unsigned, ".") {
+		unsigned = "0" + unsigned
+	}
+	if mantissa, exponent, found := strings.Cut(unsigned, "e"); found && strings.HasSuffix(mantissa, ".") {
+		unsigned = mantissa + "0e" + exponent
+	} else if strings.HasSuffix(unsigned, ".") {
+		unsigned += "0"
+	}
+	return sign + unsigned, nil
+}
+
+// isIdentStart reports whether c can start an unquoted key.
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isIdentPart reports whether c can continue an unquoted key.
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
+
+// isNumberPart reports whether c continues a number whose previous byte is prev.
+func isNumberPart(c, prev byte) bool {
+	switch {
+	case c >= '0' && c <= '9', c == '.', c == 'x', c == 'X':
+		return true
+	case (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F'):
+		return true // hex digits and exponents
+	case c == '+' || c == '-':
+		return prev == 'e' || prev == 'E'
+	}
+	return false
+}
+
+// decodeJSON5 decodes a JSONC or JSON5 document into out.
+// Errors include the line and column in the original document.
+func decodeJSON5(src []byte, out any) error {
+	normalized, err := normalizeJSON5(src)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(normalized.data, out); err != nil {
+		return jsonPositionError(src, err, normalized.original)
+	}
+	return nil
+}
+
+// decodeJSON decodes a standard JSON document into out.
+// Errors include the line and column of the problem.
+func decodeJSON(src []byte, out any) error {
+	if err := json.Unmarshal(src, out); err != nil {
+		return jsonPositionError(src, err, func(offset int64) int64 { return offset })
+	}
+	return nil
+}
+
+// jsonPositionError adds the line and column to JSON syntax and type errors,
+// mapping the decoder's offset into src with original.
+func jsonPositionError(src []byte, err error, original func(int64) int64) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// Offset is the number of bytes read when the error was detected
+		return positionError(src, original(max(syntaxErr.Offset-1, 0)), err)
+	case errors.As(err, &typeErr):
+		return positionError(src, original(max(typeErr.Offset-1, 0)), err)
+	}
+	return err
+}
+
+// positionError prefixes err with the 1-based line and column of offset in src.
+func positionError(src []byte, offset int64, err error) error {
+	offset = min(offset, int64(len(src)))
+	line := bytes.Count(src[:offset], []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(src[:offset], '\n')
+	return fmt.Errorf("line %d, column %d: %w", line, column, err)
+}
@@ -0,0 +1,111 @@
+package sources
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeJSON5(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]any
+	}{
+		{
+			name:     "line and block comments",
+			input:    "{\n  // comment\n  \"a\": 1, /* inline */ \"b\": \"//not a comment\"\n}",
+			expected: map[string]any{"a": 1.0, "b": "//not a comment"},
+		},
+		{
+			name:     "trailing commas",
+			input:    `{"a": [1, 2,], "b": {"c": true,},}`,
+			expected: map[string]any{"a": []any{1.0, 2.0}, "b": map[string]any{"c": true}},
+		},
+		{
+			name:     "trailing comma before comment",
+			input:    "{\"a\": 1, // last\n}",
+			expected: map[string]any{"a": 1.0},
+		},
+		{
+			name:     "unquoted keys",
+			input:    `{host: "localhost", $port_2: 8080}`,
+			expected: map[string]any{"host": "localhost", "$port_2": 8080.0},
+		},
+		{
+			name:     "single-quoted strings",
+			input:    `{'a': 'it\'s "quoted"'}`,
+			expected: map[string]any{"a": `it's "quoted"`},
+		},
+		{
+			name:     "json5 numbers",
+			input:    `{a: 0x1F, b: +5, c: .5, d: 5., e: -0xA, f: 1e3, g: 1.5E-2}`,
+			expected: map[string]any{"a": 31.0, "b": 5.0, "c": 0.5, "d": 5.0, "e": -10.0, "f": 1000.0, "g": 0.015},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := NormalizeJSON5([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("expected no error, got: %s", err)
+			}
+			var out map[string]any
+			if err := json.Unmarshal(normalized, &out); err != nil {
+				t.Fatalf("expected valid JSON, got: %s (%s)", err, normalized)
+			}
+			if !reflect.DeepEqual(out, tt.expected) {
+				t.Errorf("expected %v, got: %v", tt.expected, out)
+			}
+			if strings.Count(string(normalized), "\n") != strings.Count(tt.input, "\n") {
+				t.Errorf("expected line breaks to be preserved, got: %q", normalized)
+			}
+		})
+	}
+
+	t.Run("errors include line and column", func(t *testing.T) {
+		errorTests := []struct {
+			input    string
+			expected string
+		}{
+			{"{\n  a: Infinity\n}", "line 2, column 6"},
+			{"{\n  a: yes\n}", "line 2, column 6"},
+			{"{\n  /* open", "line 2, column 3"},
+			{"{\n  a: 'open\n}", "line 2, column 11"},
+		}
+		for _, tt := range errorTests {
+			_, err := NormalizeJSON5([]byte(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error at %s for %q, got: %v", tt.expected, tt.input, err)
+			}
+		}
+	})
+}
+
+func TestFromFile_JSONPositions(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected string
+	}{
+		{"json syntax error", "config.json", "{\n  \"a\": 1,\n  \"b\": }\n", "line 3, column 8"},
+		{"jsonc syntax error", "config.jsonc", "{\n  // comment\n  \"a\": 1 \"b\": 2\n}", "line 3, column 10"},
+		{"json5 syntax error after unquoted key", "config.json5", "{\n  a: 1,\n  b: [1 2]\n}", "line 3, column 9"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to create test file: %s", err)
+			}
+			_, err := FromFile(path)
+			if err == nil || !strings.Contains(err.Error(), tt.expected) {
+				t.Errorf("expected error at %s, got: %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package sources
+
+import (
+	"os"
+
+	"github.com/rs/zerolog"
+)
+
+// logger reports problems that do not fail a source, such as a cache file
+// that cannot be written. It follows zerolog's global level.
+var logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout}).
+	With().
+	Timestamp().
+	Str("package", "sources").
+	Logger()
@@ -0,0 +1,40 @@
+package sources
+
+import (
+	"maps"
+	"slices"
+)
+
+// MapSource is a configuration source holding a fixed set of values, such as
+// defaults computed at runtime.
+type MapSource struct {
+	name   string
+	values map[string]string
+}
+
+// FromMap creates a new source named "map" from a copy of values.
+func FromMap(values map[string]string) *MapSource {
+	return FromMapWithName("map", values)
+}
+
+// FromMapWithName creates a new source with the given name from a copy of
+// values. The name is reported in provenance.
+func FromMapWithName(name string, values map[string]string) *MapSource {
+	return &MapSource{name: name, values: maps.Clone(values)}
+}
+
+// Name returns the name of this source.
+func (s *MapSource) Name() string {
+	return s.name
+}
+
+// GetValue retrieves a value by key.
+func (s *MapSource) GetValue(key string) (string, bool, error) {
+	val, found := s.values[key]
+	return val, found, nil
+}
+
+// Keys returns all keys in the source, sorted.
+func (s *MapSource) Keys() []string {
+	return slices.Sorted(maps.Keys(s.values))
+}
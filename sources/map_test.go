@@ -0,0 +1,28 @@
+package sources
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFromMap(t *testing.T) {
+	values := map[string]string{"HOST": "localhost", "PORT": "8080"}
+	source := FromMap(values)
+	values["HOST"] = "changed"
+
+	if val, found, err := source.GetValue("HOST"); !found || err != nil || val != "localhost" {
+		t.Errorf("expected 'HOST' to be 'localhost', got: %q", val)
+	}
+	if _, found, _ := source.GetValue("MISSING"); found {
+		t.Error("expected 'MISSING' not to be found")
+	}
+	if keys := source.Keys(); !reflect.DeepEqual(keys, []string{"HOST", "PORT"}) {
+		t.Errorf("expected keys [HOST PORT], got: %v", keys)
+	}
+	if source.Name() != "map" {
+		t.Errorf("expected name 'map', got: %s", source.Name())
+	}
+	if named := FromMapWithName("runtime", nil); named.Name() != "runtime" {
+		t.Errorf("expected name 'runtime', got: %s", named.Name())
+	}
+}
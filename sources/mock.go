@@ -1,5 +1,10 @@
 package sources
 
+import (
+	"maps"
+	"slices"
+)
+
 // MockSource is a mock configuration source for testing.
 type MockSource struct {
 	SourceName string            // Name of the source
@@ -20,3 +25,14 @@ func (m *MockSource) GetValue(key string) (string, bool, error) {
 	val, found := m.Values[key]
 	return val, found, nil
 }
+
+// Keys returns all keys in the mock source, sorted.
+func (m *MockSource) Keys() []string {
+	return slices.Sorted(maps.Keys(m.Values))
+}
+
+// StrictKeys returns all keys in the mock source, sorted, so that it stands
+// in for a file in strict mode.
+func (m *MockSource) StrictKeys() []string {
+	return m.Keys()
+}
@@ -0,0 +1,134 @@
+package sources
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProfileEnvVar is the environment variable FromProfile reads the profile
+// from when none is given.
+const ProfileEnvVar = "APP_ENV"
+
+// profileExtensions are the file extensions FromProfile looks for, in order.
+var profileExtensions = []string{"json", "jsonc", "json5", "yaml", "yml", "env"}
+
+// FromProfile creates a file source from a base configuration file and an
+// optional profile overlay in dir, e.g. config.yaml and config.prod.yaml for
+// name "config" and profile "prod". Files may be in any supported format,
+// and the overlay may use a different format than the base.
+// When profile is empty it is read from $APP_ENV (see ProfileEnvVar); with no
+// profile only the base file is loaded.
+// The overlay is deep-merged over the base: nested objects are merged key by
+// key, while scalars and arrays in the overlay replace those in the base.
+// Nested objects are then flattened into keys joined by NestedKeySeparator,
+// like in FromFile.
+// Returns an error if the base file is missing, more than one file matches the
+// base or overlay name, or a file cannot be parsed. A missing overlay is not
+// an error.
+func FromProfile(dir, name, profile string) (*FileSource, error) {
+	return FromProfileWithOptions(dir, name, profile, FileOptions{})
+}
+
+// FromProfileWithOptions creates a profile source like FromProfile with the
+// given file options, such as a Decryptor for ENC[...] values.
+func FromProfileWithOptions(dir, name, profile string, opts FileOptions) (*FileSource, error) {
+	if profile == "" {
+		profile = os.Getenv(ProfileEnvVar)
+	}
+
+	basePath, err := findProfileFile(dir, name)
+	if err != nil {
+		return nil, err
+	}
+	if basePath == "" {
+		return nil, fmt.Errorf("no config file %s found in %s", name, dir)
+	}
+	paths := []string{basePath}
+
+	if profile != "" {
+		overlayPath, err := findProfileFile(dir, name+"."+profile)
+		if err != nil {
+			return nil, err
+		}
+		if overlayPath != "" {
+			paths = append(paths, overlayPath)
+		}
+	}
+
+	merged := make(map[string]any)
+	for _, path := range paths {
+		doc, err := readDocument(path, opts.Documents)
+		if err != nil {
+			return nil, err
+		}
+		mergeDocuments(merged, doc)
+	}
+	joinedPath := strings.Join(paths, "+")
+	kvMap, err := flattenDocument(merged)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", joinedPath, err)
+	}
+	return newFileSource("file", joinedPath, kvMap, opts)
+}
+
+// findProfileFile returns the path of the file in dir named base with a
+// supported extension, or "" if there is none.
+// Returns an error if files with more than one extension exist.
+func findProfileFile(dir, base string) (string, error) {
+	var found []string
+	for _, ext := range profileExtensions {
+		path := filepath.Join(dir, base+"."+ext)
+		info, err := os.Stat(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("error reading %s: %w", path, err)
+		}
+		if !info.IsDir() {
+			found = append(found, path)
+		}
+	}
+	if len(found) > 1 {
+		return "", fmt.Errorf("ambiguous config files: %s", strings.Join(found, ", "))
+	}
+	if len(found) == 0 {
+		return "", nil
+	}
+	return found[0], nil
+}
+
+// readDocument reads and decodes the file at path, detecting its format from
+// the extension.
+func readDocument(path string, selector DocumentSelector) (map[string]any, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	format, err := DetectFormat(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := decodeDocument(bytes, format, selector)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// mergeDocuments deep-merges src into dst. Objects present in both are merged
+// recursively; any other value in src replaces the value in dst.
+func mergeDocuments(dst, src map[string]any) {
+	for key, srcValue := range src {
+		srcMap, srcIsMap := srcValue.(map[string]any)
+		dstMap, dstIsMap := dst[key].(map[string]any)
+		if srcIsMap && dstIsMap {
+			mergeDocuments(dstMap, srcMap)
+			continue
+		}
+		dst[key] = srcValue
+	}
+}
@@ -0,0 +1,175 @@
+package sources
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeProfileFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to create test file: %s", err)
+		}
+	}
+	return dir
+}
+
+func TestFromProfile(t *testing.T) {
+	t.Run("overlay profile over base", func(t *testing.T) {
+		dir := writeProfileFiles(t, map[string]string{
+			"config.yaml":         "HOST: localhost\nPORT: 8080\n",
+			"config.prod.json":    `{"HOST": "prod.example.com"}`,
+			"config.staging.yaml": "HOST: staging.example.com\n",
+		})
+
+		source, err := FromProfile(dir, "config", "prod")
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("HOST"); val != "prod.example.com" {
+			t.Errorf("expected overlay HOST 'prod.example.com', got: %q", val)
+		}
+		if val, _, _ := source.GetValue("PORT"); val != "8080" {
+			t.Errorf("expected base PORT '8080', got: %q", val)
+		}
+		expectedName := "file:" + filepath.Join(dir, "config.yaml") + "+" + filepath.Join(dir, "config.prod.json")
+		if source.Name() != expectedName {
+			t.Errorf("expected Name() to be %q, got: %q", expectedName, source.Name())
+		}
+	})
+
+	t.Run("nested objects are merged and flattened", func(t *testing.T) {
+		dir := writeProfileFiles(t, map[string]string{
+			"config.yaml":      "DB:\n  HOST: a\n  PORT: 1\n  POOL:\n    MAX: 10\nHOSTS:\n  - x\n",
+			"config.prod.json": `{"DB": {"HOST": "b", "POOL": {"MIN": 2}}}`,
+		})
+
+		source, err := FromProfile(dir, "config", "prod")
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		expected := map[string]string{"DB_HOST": "b", "DB_PORT": "1", "DB_POOL_MAX": "10", "DB_POOL_MIN": "2"}
+		for key, want := range expected {
+			if val, found, _ := source.GetValue(key); !found || val != want {
+				t.Errorf("expected %s %q, got: %q (found %t)", key, want, val, found)
+			}
+		}
+		if keys := source.Keys(); len(keys) != len(expected) {
+			t.Errorf("expected keys %v, got: %v", expected, keys)
+		}
+	})
+
+	t.Run("jsonc and json5 files", func(t *testing.T) {
+		dir := writeProfileFiles(t, map[string]string{
+			"config.jsonc":      "{\n  // base\n  \"HOST\": \"localhost\",\n  \"PORT\": 8080,\n}",
+			"config.prod.json5": "{HOST: 'prod.example.com', PORT: 0x1F90}",
+		})
+
+		source, err := FromProfile(dir, "config", "prod")
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("HOST"); val != "prod.example.com" {
+			t.Errorf("expected overlay HOST 'prod.example.com', got: %q", val)
+		}
+		if val, _, _ := source.GetValue("PORT"); val != "8080" {
+			t.Errorf("expected overlay PORT '8080', got: %q", val)
+		}
+	})
+
+	t.Run("profile from APP_ENV", func(t *testing.T) {
+		dir := writeProfileFiles(t, map[string]string{
+			"config.yaml":         "HOST: localhost\n",
+			"config.staging.yaml": "HOST: staging.example.com\n",
+		})
+		t.Setenv(ProfileEnvVar, "staging")
+
+		source, err := FromProfile(dir, "config", "")
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("HOST"); val != "staging.example.com" {
+			t.Errorf("expected HOST 'staging.example.com', got: %q", val)
+		}
+	})
+
+	t.Run("missing overlay is not an error", func(t *testing.T) {
+		dir := writeProfileFiles(t, map[string]string{"config.env": "HOST=localhost\n"})
+
+		source, err := FromProfile(dir, "config", "dev")
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("HOST"); val != "localhost" {
+			t.Errorf("expected HOST 'localhost', got: %q", val)
+		}
+	})
+
+	t.Run("error for missing base", func(t *testing.T) {
+		dir := writeProfileFiles(t, map[string]string{"config.prod.yaml": "HOST: prod\n"})
+
+		if _, err := FromProfile(dir, "config", "prod"); err == nil {
+			t.Error("expected error when base file is missing")
+		}
+	})
+
+	t.Run("error for ambiguous files", func(t *testing.T) {
+		dir := writeProfileFiles(t, map[string]string{
+			"config.yaml": "HOST: localhost\n",
+			"config.json": `{"HOST": "localhost"}`,
+		})
+
+		_, err := FromProfile(dir, "config", "")
+		if err == nil || !strings.Contains(err.Error(), "ambiguous") {
+			t.Errorf("expected ambiguous files error, got: %v", err)
+		}
+	})
+
+	t.Run("error for invalid overlay", func(t *testing.T) {
+		dir := writeProfileFiles(t, map[string]string{
+			"config.yaml":      "HOST: localhost\n",
+			"config.prod.json": `{"HOST": `,
+		})
+
+		_, err := FromProfile(dir, "config", "prod")
+		if err == nil || !strings.Contains(err.Error(), "config.prod.json") {
+			t.Errorf("expected error naming the overlay, got: %v", err)
+		}
+	})
+}
+
+func TestMergeDocuments(t *testing.T) {
+	base := map[string]any{
+		"HOST": "localhost",
+		"db": map[string]any{
+			"host": "localhost",
+			"port": 5432,
+		},
+		"hosts": []any{"a", "b"},
+	}
+	overlay := map[string]any{
+		"db": map[string]any{
+			"host": "db.prod",
+		},
+		"hosts": []any{"c"},
+	}
+
+	mergeDocuments(base, overlay)
+
+	expected := map[string]any{
+		"HOST": "localhost",
+		"db": map[string]any{
+			"host": "db.prod",
+			"port": 5432,
+		},
+		"hosts": []any{"c"},
+	}
+	if !reflect.DeepEqual(base, expected) {
+		t.Errorf("expected %v, got: %v", expected, base)
+	}
+}
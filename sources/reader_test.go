@@ -0,0 +1,84 @@
+package sources
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"defaults/config.yaml": {Data: []byte("HOST: localhost\nPORT: 8080\n")},
+		"defaults/config.txt":  {Data: []byte("HOST=localhost\n")},
+	}
+
+	t.Run("read embedded file", func(t *testing.T) {
+		source, err := FromFS(fsys, "defaults/config.yaml")
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("PORT"); val != "8080" {
+			t.Errorf("expected 'PORT' to be '8080', got: %q", val)
+		}
+		if source.Name() != "fs:defaults/config.yaml" {
+			t.Errorf("expected name 'fs:defaults/config.yaml', got: %s", source.Name())
+		}
+	})
+
+	t.Run("error for missing file", func(t *testing.T) {
+		if _, err := FromFS(fsys, "defaults/missing.yaml"); err == nil {
+			t.Error("expected error for missing file")
+		}
+	})
+
+	t.Run("error for unsupported extension", func(t *testing.T) {
+		if _, err := FromFS(fsys, "defaults/config.txt"); err == nil {
+			t.Error("expected error for unsupported extension")
+		}
+	})
+}
+
+// failingReader returns an error on every read.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestFromReader(t *testing.T) {
+	t.Run("explicit format", func(t *testing.T) {
+		source, err := FromReader(strings.NewReader("HOST=localhost\n"), FormatEnv, "stdin")
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("HOST"); val != "localhost" {
+			t.Errorf("expected 'HOST' to be 'localhost', got: %q", val)
+		}
+		if source.Name() != "reader:stdin" {
+			t.Errorf("expected name 'reader:stdin', got: %s", source.Name())
+		}
+	})
+
+	t.Run("format detected from name", func(t *testing.T) {
+		source, err := FromReader(strings.NewReader(`{"HOST": "localhost"}`), "", "pipeline.json")
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("HOST"); val != "localhost" {
+			t.Errorf("expected 'HOST' to be 'localhost', got: %q", val)
+		}
+	})
+
+	t.Run("error when format cannot be detected", func(t *testing.T) {
+		if _, err := FromReader(strings.NewReader("HOST=localhost\n"), "", "stdin"); err == nil {
+			t.Error("expected error when format cannot be detected")
+		}
+	})
+
+	t.Run("error for read failure", func(t *testing.T) {
+		if _, err := FromReader(failingReader{}, FormatJSON, "stdin"); err == nil {
+			t.Error("expected error for read failure")
+		}
+	})
+}
@@ -1,5 +1,7 @@
 package sources
 
+import "context"
+
 // Source is an interface for retrieving configuration values.
 type Source interface {
 	// Name returns the name of the configuration source.
@@ -8,3 +10,47 @@ type Source interface {
 	// Returns the value, whether it was found, and any error that occurred.
 	GetValue(key string) (val string, found bool, err error)
 }
+
+// KeyLister is an optional capability for sources that can enumerate the keys
+// they hold, such as files, directories, and key-value stores.
+type KeyLister interface {
+	// Keys returns all keys available in the source, sorted.
+	Keys() []string
+}
+
+// StrictKeyLister is an optional capability for sources whose keys are all
+// meant for the loader, such as files, URL documents, and command-line
+// arguments. The loader uses it in strict mode to detect keys that are not
+// consumed by any field. Sources whose keys may belong to other applications,
+// such as directories, maps, and key-value stores, do not implement it.
+type StrictKeyLister interface {
+	// StrictKeys returns the keys that strict mode checks, sorted.
+	StrictKeys() []string
+}
+
+// Watcher is an optional capability for sources whose values can change while
+// the application is running, such as mounted ConfigMaps.
+type Watcher interface {
+	// Watch blocks until ctx is cancelled, calling onChange after the source's
+	// values have changed. The new values are visible through GetValue by the
+	// time onChange is called. Returns ctx.Err() once ctx is cancelled.
+	Watch(ctx context.Context, onChange func()) error
+}
+
+// SecretMarker is an optional capability for sources that know which of their
+// values are secrets, such as decrypted ENC[...] file values. The loader
+// masks secret values in logs and error messages and marks them as secret in
+// provenance.
+type SecretMarker interface {
+	// IsSecret reports whether the value held under key is a secret.
+	IsSecret(key string) bool
+}
+
+// Wrapper is an optional capability for sources that delegate lookups to
+// other sources, such as the combinators in this package. The loader uses it
+// to attribute values to the underlying source in provenance.
+type Wrapper interface {
+	// Underlying returns the wrapped source that holds key and the key the
+	// value is stored under there. Returns false if no wrapped source holds it.
+	Underlying(key string) (Source, string, bool)
+}
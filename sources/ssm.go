@@ -0,0 +1,186 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultSSMMaxRetries is how many times a throttled request is retried
+	// when SSMConfig.MaxRetries is not set.
+	defaultSSMMaxRetries = 5
+	// ssmBaseBackoff is the delay before the first retry of a throttled
+	// request; it doubles on each further retry.
+	ssmBaseBackoff = 200 * time.Millisecond
+	// ssmMaxBackoff caps the delay between retries.
+	ssmMaxBackoff = 5 * time.Second
+)
+
+// SSMParameter is a parameter returned by SSM Parameter Store.
+type SSMParameter struct {
+	Name  string `json:"Name"`  // Full parameter name, e.g. /app/prod/db/password
+	Value string `json:"Value"` // Parameter value, decrypted for SecureString parameters
+	Type  string `json:"Type"`  // String, StringList, or SecureString
+}
+
+// SSMClient is the subset of the SSM Parameter Store and Secrets Manager APIs
+// used by SSMSource. AWSClient implements it over HTTP; tests and callers
+// using the AWS SDK can provide their own implementation.
+type SSMClient interface {
+	// GetParametersByPath returns one page of the parameters under path,
+	// recursively and decrypted, and the token for the next page ("" if none).
+	GetParametersByPath(ctx context.Context, path, nextToken string) ([]SSMParameter, string, error)
+	// GetSecretValue returns the SecretString of a Secrets Manager secret.
+	GetSecretValue(ctx context.Context, secretID string) (string, error)
+}
+
+// SSMConfig contains configuration options for creating an SSMSource.
+type SSMConfig struct {
+	Client SSMClient // Client used to call AWS (required)
+	Path   string    // Parameter path to load recursively, e.g. "/app/prod/"
+	// SecretIDs lists Secrets Manager secrets holding JSON objects; each
+	// top-level field becomes a key.
+	SecretIDs []string
+	// KeyFunc maps parameter names, relative to Path, and secret fields to
+	// configly keys (defaults to the name unchanged).
+	KeyFunc    func(name string) string
+	MaxRetries int // Retries for throttled requests (defaults to 5)
+}
+
+// SSMSource is a configuration source backed by AWS SSM Parameter Store and,
+// optionally, Secrets Manager. All parameters under the path are fetched at
+// construction, following pagination and retrying throttled requests with
+// exponential backoff. SecureString parameters and Secrets Manager fields are
+// reported as secrets (see SecretMarker).
+type SSMSource struct {
+	kvMap   map[string]string
+	secrets map[string]bool // Keys whose values are SecureString parameters or Secrets Manager fields
+	path    string
+}
+
+// FromSSM creates a new SSM Parameter Store configuration source.
+// Parameters take precedence over Secrets Manager fields with the same key.
+// Returns an error if no client is configured, a request fails after all
+// retries, or a secret is not a JSON object.
+func FromSSM(cfg SSMConfig) (*SSMSource, error) {
+	return fromSSM(context.Background(), cfg, sleepContext)
+}
+
+// fromSSM implements FromSSM with an injectable sleep function for tests.
+func fromSSM(ctx context.Context, cfg SSMConfig, sleep func(context.Context, time.Duration) bool) (*SSMSource, error) {
+	if cfg.Client == nil {
+		return nil, errors.New("ssm client is required")
+	}
+	if cfg.Path == "" && len(cfg.SecretIDs) == 0 {
+		return nil, errors.New("ssm path or secret IDs are required")
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = defaultSSMMaxRetries
+	}
+	mapKey := func(name string) string {
+		if cfg.KeyFunc != nil {
+			return cfg.KeyFunc(name)
+		}
+		return name
+	}
+	retry := func(call func() error) error {
+		return retryThrottled(ctx, cfg.MaxRetries, sleep, call)
+	}
+
+	kvMap := make(map[string]string)
+	secrets := make(map[string]bool)
+	for _, secretID := range cfg.SecretIDs {
+		var secret string
+		err := retry(func() (err error) {
+			secret, err = cfg.Client.GetSecretValue(ctx, secretID)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error getting secret %s: %w", secretID, err)
+		}
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+			return nil, fmt.Errorf("error parsing secret %s: %w", secretID, err)
+		}
+		for name, value := range fields {
+			if str, ok := ScalarString(value); ok {
+				key := mapKey(name)
+				kvMap[key] = str
+				secrets[key] = true
+			}
+		}
+	}
+
+	if cfg.Path != "" {
+		nextToken := ""
+		for {
+			var params []SSMParameter
+			var pageToken string
+			err := retry(func() (err error) {
+				params, pageToken, err = cfg.Client.GetParametersByPath(ctx, cfg.Path, nextToken)
+				return err
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error getting parameters under %s: %w", cfg.Path, err)
+			}
+			for _, param := range params {
+				name := strings.TrimPrefix(strings.TrimPrefix(param.Name, cfg.Path), "/")
+				key := mapKey(name)
+				kvMap[key] = param.Value
+				secrets[key] = param.Type == "SecureString"
+			}
+			if pageToken == "" {
+				break
+			}
+			nextToken = pageToken
+		}
+	}
+
+	return &SSMSource{kvMap: kvMap, secrets: secrets, path: cfg.Path}, nil
+}
+
+// retryThrottled calls call until it succeeds, fails with an error that is
+// not throttling, or maxRetries retries have been made, backing off
+// exponentially between attempts.
+func retryThrottled(ctx context.Context, maxRetries int, sleep func(context.Context, time.Duration) bool, call func() error) error {
+	backoff := ssmBaseBackoff
+	for attempt := 0; ; attempt++ {
+		err := call()
+		var throttled interface{ Throttled() bool }
+		if err == nil || attempt >= maxRetries || !errors.As(err, &throttled) || !throttled.Throttled() {
+			return err
+		}
+		if !sleep(ctx, backoff) {
+			return ctx.Err()
+		}
+		backoff = min(backoff*2, ssmMaxBackoff)
+	}
+}
+
+// Name returns the name of this source.
+func (s *SSMSource) Name() string {
+	return fmt.Sprintf("ssm:%s", s.path)
+}
+
+// GetValue retrieves a parameter or secret field by key.
+func (s *SSMSource) GetValue(key string) (string, bool, error) {
+	val, found := s.kvMap[key]
+	return val, found, nil
+}
+
+// IsSecret reports whether the value of key is a SecureString parameter or a
+// Secrets Manager field.
+func (s *SSMSource) IsSecret(key string) bool {
+	return s.secrets[key]
+}
+
+// Keys returns all keys loaded from Parameter Store and Secrets Manager, sorted.
+func (s *SSMSource) Keys() []string {
+	return slices.Sorted(maps.Keys(s.kvMap))
+}
@@ -0,0 +1,240 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSSM is a local mock of the SSM Parameter Store and Secrets Manager JSON
+// APIs that pages results and throttles a configurable number of requests.
+type fakeSSM struct {
+	mu         sync.Mutex
+	params     []SSMParameter
+	secrets    map[string]string
+	pageSize   int
+	throttle   int // number of requests to reject with ThrottlingException
+	requests   int
+	authHeader string
+}
+
+func (f *fakeSSM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	f.authHeader = r.Header.Get("Authorization")
+
+	if f.throttle > 0 {
+		f.throttle--
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"__type":"com.amazonaws.ssm#ThrottlingException","message":"Rate exceeded"}`))
+		return
+	}
+
+	var body map[string]any
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	switch r.Header.Get("X-Amz-Target") {
+	case "AmazonSSM.GetParametersByPath":
+		if body["WithDecryption"] != true || body["Recursive"] != true {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		start := 0
+		if token, ok := body["NextToken"].(string); ok {
+			start = len(token)
+		}
+		end := min(start+f.pageSize, len(f.params))
+		resp := map[string]any{"Parameters": f.params[start:end]}
+		if end < len(f.params) {
+			resp["NextToken"] = strings.Repeat("x", end)
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	case "secretsmanager.GetSecretValue":
+		secret, ok := f.secrets[body["SecretId"].(string)]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"not found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"SecretString": secret})
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func newFakeSSMClient(t *testing.T, fake *fakeSSM) SSMClient {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	client, err := NewAWSClient(AWSConfig{
+		Region:          "us-east-1",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		Endpoint:        server.URL,
+	})
+	if err != nil {
+		t.Fatalf("failed to create client: %s", err)
+	}
+	return client
+}
+
+func noSleep(ctx context.Context, d time.Duration) bool {
+	return ctx.Err() == nil
+}
+
+func TestFromSSM(t *testing.T) {
+	params := []SSMParameter{
+		{Name: "/app/prod/db/host", Value: "db.internal", Type: "String"},
+		{Name: "/app/prod/db/password", Value: "s3cret", Type: "SecureString"},
+		{Name: "/app/prod/port", Value: "8080", Type: "String"},
+	}
+
+	t.Run("fetch path across pages", func(t *testing.T) {
+		fake := &fakeSSM{params: params, pageSize: 2}
+		source, err := fromSSM(context.Background(), SSMConfig{
+			Client:  newFakeSSMClient(t, fake),
+			Path:    "/app/prod/",
+			KeyFunc: UpperSnakeKey,
+		}, noSleep)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+
+		keys := source.Keys()
+		if len(keys) != 3 || keys[0] != "DB_HOST" || keys[1] != "DB_PASSWORD" || keys[2] != "PORT" {
+			t.Errorf("expected keys [DB_HOST DB_PASSWORD PORT], got: %v", keys)
+		}
+		val, found, _ := source.GetValue("DB_PASSWORD")
+		if !found || val != "s3cret" {
+			t.Errorf("expected 'DB_PASSWORD' to be 's3cret', got: %q", val)
+		}
+		if !source.IsSecret("DB_PASSWORD") || source.IsSecret("DB_HOST") {
+			t.Error("expected only the SecureString parameter to be secret")
+		}
+		if fake.requests != 2 {
+			t.Errorf("expected 2 paged requests, got: %d", fake.requests)
+		}
+		if !strings.HasPrefix(fake.authHeader, "AWS4-HMAC-SHA256 Credential=AKID/") {
+			t.Errorf("expected signed request, got Authorization: %s", fake.authHeader)
+		}
+	})
+
+	t.Run("retry throttled requests", func(t *testing.T) {
+		fake := &fakeSSM{params: params, pageSize: 10, throttle: 2}
+		source, err := fromSSM(context.Background(), SSMConfig{
+			Client: newFakeSSMClient(t, fake),
+			Path:   "/app/prod/",
+		}, noSleep)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if _, found, _ := source.GetValue("port"); !found {
+			t.Error("expected 'port' to be found")
+		}
+		if fake.requests != 3 {
+			t.Errorf("expected 3 requests, got: %d", fake.requests)
+		}
+	})
+
+	t.Run("give up after max retries", func(t *testing.T) {
+		fake := &fakeSSM{params: params, pageSize: 10, throttle: 10}
+		_, err := fromSSM(context.Background(), SSMConfig{
+			Client:     newFakeSSMClient(t, fake),
+			Path:       "/app/prod/",
+			MaxRetries: 2,
+		}, noSleep)
+		var awsErr *AWSError
+		if !errors.As(err, &awsErr) || awsErr.Code != "ThrottlingException" {
+			t.Errorf("expected ThrottlingException, got: %v", err)
+		}
+		if fake.requests != 3 {
+			t.Errorf("expected 3 requests, got: %d", fake.requests)
+		}
+	})
+
+	t.Run("parse Secrets Manager JSON secret into keys", func(t *testing.T) {
+		fake := &fakeSSM{
+			params:   params,
+			pageSize: 10,
+			secrets:  map[string]string{"app/db": `{"DB_USER":"admin","DB_PASSWORD":"from-secret","DB_PORT":5432}`},
+		}
+		source, err := fromSSM(context.Background(), SSMConfig{
+			Client:    newFakeSSMClient(t, fake),
+			Path:      "/app/prod/",
+			SecretIDs: []string{"app/db"},
+			KeyFunc:   UpperSnakeKey,
+		}, noSleep)
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+
+		if val, _, _ := source.GetValue("DB_USER"); val != "admin" {
+			t.Errorf("expected 'DB_USER' to be 'admin', got: %q", val)
+		}
+		if val, _, _ := source.GetValue("DB_PORT"); val != "5432" {
+			t.Errorf("expected 'DB_PORT' to be '5432', got: %q", val)
+		}
+		if val, _, _ := source.GetValue("DB_PASSWORD"); val != "s3cret" {
+			t.Errorf("expected parameter to take precedence over secret, got: %q", val)
+		}
+		for _, key := range []string{"DB_USER", "DB_PORT", "DB_PASSWORD"} {
+			if !source.IsSecret(key) {
+				t.Errorf("expected %s to be secret", key)
+			}
+		}
+		if source.IsSecret("PORT") {
+			t.Error("expected String parameter PORT not to be secret")
+		}
+	})
+
+	t.Run("error for missing secret", func(t *testing.T) {
+		fake := &fakeSSM{secrets: map[string]string{}}
+		_, err := fromSSM(context.Background(), SSMConfig{
+			Client:    newFakeSSMClient(t, fake),
+			SecretIDs: []string{"missing"},
+		}, noSleep)
+		if err == nil {
+			t.Error("expected error for missing secret")
+		}
+	})
+
+	t.Run("error for non-object secret", func(t *testing.T) {
+		fake := &fakeSSM{secrets: map[string]string{"plain": "just-a-string"}}
+		_, err := fromSSM(context.Background(), SSMConfig{
+			Client:    newFakeSSMClient(t, fake),
+			SecretIDs: []string{"plain"},
+		}, noSleep)
+		if err == nil {
+			t.Error("expected error for secret that is not a JSON object")
+		}
+	})
+
+	t.Run("error without client", func(t *testing.T) {
+		source, err := FromSSM(SSMConfig{Path: "/app/prod/"})
+		if err == nil {
+			t.Error("expected error without client")
+		}
+		if source != nil {
+			t.Error("expected source to be nil on error")
+		}
+	})
+}
+
+func TestSSMSource_Name(t *testing.T) {
+	source, err := fromSSM(context.Background(), SSMConfig{
+		Client: newFakeSSMClient(t, &fakeSSM{pageSize: 10}),
+		Path:   "/app/prod/",
+	}, noSleep)
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+	if name := source.Name(); name != "ssm:/app/prod/" {
+		t.Errorf("expected Name() to return 'ssm:/app/prod/', got: %s", name)
+	}
+}
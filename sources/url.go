@@ -0,0 +1,289 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultURLTimeout is the request timeout used by URLSource when
+// URLOptions.Timeout is not set.
+const DefaultURLTimeout = 10 * time.Second
+
+// URLOptions configures a URLSource.
+type URLOptions struct {
+	// Format of the document. When empty it is detected from the response's
+	// Content-Type, then from the URL path's extension.
+	Format      Format
+	Headers     map[string]string // Extra request headers
+	BearerToken string            // Sent as "Authorization: Bearer <token>" when set
+	Username    string            // Username for HTTP basic auth (optional)
+	Password    string            // Password for HTTP basic auth (optional)
+	Timeout     time.Duration     // Per-request timeout (defaults to DefaultURLTimeout)
+	// PollInterval is how often Watch polls the endpoint
+	// (defaults to DefaultPollInterval).
+	PollInterval time.Duration
+	// CacheFile, when set, stores the last successfully fetched document.
+	// If the endpoint cannot be reached at startup, the cached copy is used.
+	// Failing to write the cache is logged and does not fail the fetch.
+	CacheFile  string
+	HTTPClient *http.Client // HTTP client used for requests (defaults to http.DefaultClient)
+}
+
+// URLSource is a configuration source that fetches a JSON, YAML, or dotenv
+// document over HTTP(S). Documents are parsed with the same parsers as
+// FromFile. Watch polls the endpoint using ETag/If-None-Match so unchanged
+// documents are not re-downloaded.
+type URLSource struct {
+	mu     sync.RWMutex
+	url    string
+	opts   URLOptions
+	client *http.Client
+	kvMap  map[string]string
+	etag   string
+}
+
+// urlCache is the on-disk representation of a last-known-good document.
+type urlCache struct {
+	ETag   string `json:"etag"`
+	Format Format `json:"format"`
+	Body   string `json:"body"`
+}
+
+// FromURL creates a new URL configuration source and fetches the document.
+// If the fetch fails and a CacheFile from a previous successful fetch
+// exists, the cached document is used instead and the failure is logged.
+// Returns an error if the URL is invalid, or if the fetch fails and no
+// cached copy is available.
+func FromURL(rawURL string, opts URLOptions) (*URLSource, error) {
+	if _, err := url.ParseRequestURI(rawURL); err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultURLTimeout
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = DefaultPollInterval
+	}
+
+	s := &URLSource{
+		url:    rawURL,
+		opts:   opts,
+		client: opts.HTTPClient,
+	}
+	if s.client == nil {
+		s.client = http.DefaultClient
+	}
+
+	kvMap, etag, _, err := s.fetch(context.Background(), "")
+	if err != nil {
+		cached, cacheErr := s.readCache()
+		if cacheErr != nil {
+			return nil, err
+		}
+		logger.Warn().Err(err).Str("url", s.url).Str("cacheFile", opts.CacheFile).Msg("using cached document")
+		kvMap, etag = cached, ""
+	}
+	s.kvMap = kvMap
+	s.etag = etag
+	return s, nil
+}
+
+// fetch requests the document, sending If-None-Match when etag is set.
+// Returns the parsed document and its ETag, or modified=false if the server
+// responded 304 Not Modified.
+func (s *URLSource) fetch(ctx context.Context, etag string) (map[string]string, string, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("error creating request: %w", err)
+	}
+	for name, value := range s.opts.Headers {
+		req.Header.Set(name, value)
+	}
+	if s.opts.BearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.opts.BearerToken)
+	} else if s.opts.Username != "" {
+		req.SetBasicAuth(s.opts.Username, s.opts.Password)
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("error requesting %s: %w", s.url, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotModified {
+		return nil, etag, false, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, "", false, fmt.Errorf("error requesting %s: status %d", s.url, res.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, DefaultMaxFileSize+1))
+	if err != nil {
+		return nil, "", false, fmt.Errorf("error reading response from %s: %w", s.url, err)
+	}
+	if int64(len(body)) > DefaultMaxFileSize {
+		return nil, "", false, fmt.Errorf("response from %s exceeds maximum size of %d bytes", s.url, DefaultMaxFileSize)
+	}
+	format, err := s.format(res.Header.Get("Content-Type"))
+	if err != nil {
+		return nil, "", false, err
+	}
+	kvMap, err := parseDocument(body, format, nil)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	newETag := res.Header.Get("ETag")
+	if err := s.writeCache(urlCache{ETag: newETag, Format: format, Body: string(body)}); err != nil {
+		logger.Warn().Err(err).Str("url", s.url).Str("cacheFile", s.opts.CacheFile).Msg("keeping fetched document without caching it")
+	}
+	return kvMap, newETag, true, nil
+}
+
+// format determines the document format from the explicit option, the
+// response Content-Type, or the URL path, in that order.
+func (s *URLSource) format(contentType string) (Format, error) {
+	if s.opts.Format != "" {
+		return s.opts.Format, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "application/json":
+		return FormatJSON, nil
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return FormatYAML, nil
+	}
+	u, _ := url.Parse(s.url)
+	format, err := DetectFormat(u.Path)
+	if err != nil {
+		return "", fmt.Errorf("cannot determine format of %s (content type %q): %w", s.url, contentType, err)
+	}
+	return format, nil
+}
+
+// writeCache atomically stores the last fetched document in CacheFile.
+func (s *URLSource) writeCache(cache urlCache) error {
+	if s.opts.CacheFile == "" {
+		return nil
+	}
+	data, err := json.Marshal(cache)
+	if err != nil {
+		return fmt.Errorf("error encoding cache: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.opts.CacheFile), filepath.Base(s.opts.CacheFile)+".*")
+	if err != nil {
+		return fmt.Errorf("error writing cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.opts.CacheFile); err != nil {
+		return fmt.Errorf("error writing cache: %w", err)
+	}
+	return nil
+}
+
+// readCache loads and parses the last-known-good document from CacheFile.
+func (s *URLSource) readCache() (map[string]string, error) {
+	if s.opts.CacheFile == "" {
+		return nil, errors.New("no cache file configured")
+	}
+	data, err := os.ReadFile(s.opts.CacheFile)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cache: %w", err)
+	}
+	var cache urlCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, fmt.Errorf("error decoding cache: %w", err)
+	}
+	return parseDocument([]byte(cache.Body), cache.Format, nil)
+}
+
+// Name returns the name of this source.
+func (s *URLSource) Name() string {
+	return fmt.Sprintf("url:%s", s.url)
+}
+
+// GetValue retrieves a value by key from the last fetched document.
+func (s *URLSource) GetValue(key string) (string, bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	val, found := s.kvMap[key]
+	return val, found, nil
+}
+
+// Keys returns all scalar keys of the last fetched document, sorted.
+func (s *URLSource) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return slices.Sorted(maps.Keys(s.kvMap))
+}
+
+// StrictKeys returns all scalar keys of the last fetched document, sorted.
+func (s *URLSource) StrictKeys() []string {
+	return s.Keys()
+}
+
+// Watch polls the endpoint every PollInterval with If-None-Match and calls
+// onChange when a new document changes any value. Failed polls are logged,
+// keep the current values, and are retried on the next poll.
+// Returns ctx.Err() once ctx is cancelled.
+func (s *URLSource) Watch(ctx context.Context, onChange func()) error {
+	ticker := time.NewTicker(s.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			s.mu.RLock()
+			etag := s.etag
+			s.mu.RUnlock()
+
+			kvMap, newETag, modified, err := s.fetch(ctx, etag)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				logger.Warn().Err(err).Str("url", s.url).Msg("keeping previous document")
+				continue
+			}
+			if !modified {
+				continue
+			}
+			s.mu.Lock()
+			changed := !maps.Equal(s.kvMap, kvMap)
+			s.kvMap = kvMap
+			s.etag = newETag
+			s.mu.Unlock()
+			if changed {
+				onChange()
+			}
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeConfigServer serves a configuration document with an ETag and counts
+// full and conditional responses.
+type fakeConfigServer struct {
+	mu          sync.Mutex
+	body        string
+	contentType string
+	etag        string
+	down        bool
+	full        int
+	notModified int
+	lastRequest *http.Request
+}
+
+func (f *fakeConfigServer) set(body, etag string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.body, f.etag = body, etag
+}
+
+func (f *fakeConfigServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastRequest = r
+	if f.down {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if f.etag != "" && r.Header.Get("If-None-Match") == f.etag {
+		f.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	f.full++
+	if f.contentType != "" {
+		w.Header().Set("Content-Type", f.contentType)
+	}
+	w.Header().Set("ETag", f.etag)
+	_, _ = w.Write([]byte(f.body))
+}
+
+func TestFromURL(t *testing.T) {
+	t.Run("detect format from Content-Type", func(t *testing.T) {
+		fake := &fakeConfigServer{body: `{"PORT": 8080}`, contentType: "application/json; charset=utf-8"}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		source, err := FromURL(server.URL+"/config", URLOptions{})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		val, found, _ := source.GetValue("PORT")
+		if !found || val != "8080" {
+			t.Errorf("expected 'PORT' to be '8080', got: %q", val)
+		}
+	})
+
+	t.Run("detect format from URL path", func(t *testing.T) {
+		fake := &fakeConfigServer{body: "PORT: 8080\nHOST: localhost\n", contentType: "text/plain"}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		source, err := FromURL(server.URL+"/config.yaml", URLOptions{})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if keys := source.Keys(); len(keys) != 2 {
+			t.Errorf("expected 2 keys, got: %v", keys)
+		}
+	})
+
+	t.Run("explicit format", func(t *testing.T) {
+		fake := &fakeConfigServer{body: "PORT=8080\n", contentType: "text/plain"}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		source, err := FromURL(server.URL+"/config", URLOptions{Format: FormatEnv})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("PORT"); val != "8080" {
+			t.Errorf("expected 'PORT' to be '8080', got: %q", val)
+		}
+	})
+
+	t.Run("error for unknown format", func(t *testing.T) {
+		fake := &fakeConfigServer{body: "PORT=8080\n", contentType: "text/plain"}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		if _, err := FromURL(server.URL+"/config", URLOptions{}); err == nil {
+			t.Error("expected error when format cannot be determined")
+		}
+	})
+
+	t.Run("send headers and auth", func(t *testing.T) {
+		fake := &fakeConfigServer{body: `{}`, contentType: "application/json"}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		_, err := FromURL(server.URL, URLOptions{
+			Headers:     map[string]string{"X-Team": "platform"},
+			BearerToken: "token",
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if got := fake.lastRequest.Header.Get("X-Team"); got != "platform" {
+			t.Errorf("expected X-Team header 'platform', got: %q", got)
+		}
+		if got := fake.lastRequest.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("expected bearer Authorization header, got: %q", got)
+		}
+
+		_, err = FromURL(server.URL, URLOptions{Username: "user", Password: "pass"})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if user, pass, ok := fake.lastRequest.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			t.Errorf("expected basic auth user:pass, got: %s:%s", user, pass)
+		}
+	})
+
+	t.Run("error for non-OK status", func(t *testing.T) {
+		fake := &fakeConfigServer{down: true}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		source, err := FromURL(server.URL+"/config.json", URLOptions{})
+		if err == nil {
+			t.Error("expected error when endpoint is down")
+		}
+		if source != nil {
+			t.Error("expected source to be nil on error")
+		}
+	})
+
+	t.Run("error for invalid URL", func(t *testing.T) {
+		if _, err := FromURL("not a url", URLOptions{}); err == nil {
+			t.Error("expected error for invalid URL")
+		}
+	})
+
+	t.Run("use last-known-good cache when endpoint is down", func(t *testing.T) {
+		cacheFile := filepath.Join(t.TempDir(), "config.cache")
+		fake := &fakeConfigServer{body: `{"PORT": 8080}`, contentType: "application/json"}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		if _, err := FromURL(server.URL, URLOptions{CacheFile: cacheFile}); err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+
+		fake.down = true
+		source, err := FromURL(server.URL, URLOptions{CacheFile: cacheFile})
+		if err != nil {
+			t.Fatalf("expected cached document to be used, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("PORT"); val != "8080" {
+			t.Errorf("expected cached 'PORT' to be '8080', got: %q", val)
+		}
+	})
+
+	t.Run("unwritable cache does not fail fetches", func(t *testing.T) {
+		cacheFile := filepath.Join(t.TempDir(), "missing", "config.cache")
+		fake := &fakeConfigServer{body: `{"PORT": 8080}`, contentType: "application/json", etag: `"v1"`}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		source, err := FromURL(server.URL, URLOptions{CacheFile: cacheFile, PollInterval: 10 * time.Millisecond})
+		if err != nil {
+			t.Fatalf("expected fetched document to be used, got: %s", err)
+		}
+		if val, _, _ := source.GetValue("PORT"); val != "8080" {
+			t.Errorf("expected 'PORT' to be '8080', got: %q", val)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		changed := make(chan struct{}, 1)
+		go func() {
+			_ = source.Watch(ctx, func() {
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			})
+		}()
+		fake.set(`{"PORT": 9090}`, `"v2"`)
+
+		select {
+		case <-changed:
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected updates to be applied without a cache")
+		}
+		if val, _, _ := source.GetValue("PORT"); val != "9090" {
+			t.Errorf("expected updated 'PORT' to be '9090', got: %q", val)
+		}
+	})
+}
+
+func TestURLSource_Name(t *testing.T) {
+	server := httptest.NewServer(&fakeConfigServer{body: `{}`, contentType: "application/json"})
+	defer server.Close()
+
+	source, err := FromURL(server.URL+"/config", URLOptions{})
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+	if name := source.Name(); name != "url:"+server.URL+"/config" {
+		t.Errorf("expected Name() to return 'url:%s/config', got: %s", server.URL, name)
+	}
+}
+
+func TestURLSource_Watch(t *testing.T) {
+	fake := &fakeConfigServer{body: `{"LEVEL": "info"}`, contentType: "application/json", etag: `"v1"`}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	source, err := FromURL(server.URL, URLOptions{PollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	changed := make(chan struct{}, 1)
+	done := make(chan error, 1)
+	go func() {
+		done <- source.Watch(ctx, func() {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		})
+	}()
+
+	// let a few conditional polls hit the unchanged document
+	time.Sleep(50 * time.Millisecond)
+	fake.set(`{"LEVEL": "debug"}`, `"v2"`)
+
+	select {
+	case <-changed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected onChange to be called after document update")
+	}
+	if val, _, _ := source.GetValue("LEVEL"); val != "debug" {
+		t.Errorf("expected updated value 'debug', got: %q", val)
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled after cancel, got: %v", err)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	if fake.notModified == 0 {
+		t.Error("expected conditional requests to be answered with 304")
+	}
+	if fake.full != 2 {
+		t.Errorf("expected 2 full responses, got: %d", fake.full)
+	}
+}
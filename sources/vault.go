@@ -0,0 +1,319 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultVaultCacheTTL is how long a fetched secret is reused when Vault
+	// does not report a lease duration for it, as is the case for KV secrets.
+	DefaultVaultCacheTTL = 5 * time.Minute
+	// DefaultVaultTimeout is the request timeout used by VaultSource when
+	// VaultConfig.Timeout is not set.
+	DefaultVaultTimeout = 10 * time.Second
+	// defaultAppRoleMount is the mount path of the AppRole auth method when
+	// VaultAppRole.MountPath is not set.
+	defaultAppRoleMount = "approle"
+	// vaultRenewMargin is how long before expiry a token is renewed.
+	vaultRenewMargin = 10 * time.Second
+)
+
+// VaultAppRole contains the credentials for Vault's AppRole auth method.
+type VaultAppRole struct {
+	RoleID    string // The role ID of the AppRole
+	SecretID  string // The secret ID issued for the AppRole
+	MountPath string // Mount path of the auth method (defaults to "approle")
+}
+
+// VaultConfig contains configuration options for creating a VaultSource.
+type VaultConfig struct {
+	Address   string        // Vault server address (e.g. https://vault.example.com:8200)
+	Token     string        // Token for token auth; ignored when AppRole is set
+	AppRole   *VaultAppRole // AppRole credentials, used to log in instead of a static token
+	Namespace string        // Vault Enterprise namespace (optional)
+	// Keys maps configly keys to secret references in the form
+	// "<path>#<field>", e.g. "secret/data/app#db_password" for KV v2.
+	Keys       map[string]string
+	CacheTTL   time.Duration // How long secrets without a lease are cached (defaults to DefaultVaultCacheTTL)
+	Timeout    time.Duration // Per-request timeout (defaults to DefaultVaultTimeout)
+	HTTPClient *http.Client  // HTTP client used for requests (defaults to http.DefaultClient)
+}
+
+// VaultSource is a configuration source backed by the HashiCorp Vault HTTP
+// API. It reads fields of KV v1 and v2 secrets, caching each secret until its
+// lease expires (or for CacheTTL when it has none) and re-fetching it on the
+// next lookup after that. Tokens obtained through AppRole are renewed before
+// they expire and re-issued by logging in again when renewal fails.
+type VaultSource struct {
+	mu          sync.Mutex
+	cfg         VaultConfig
+	client      *http.Client
+	token       string
+	tokenExpiry time.Time // zero when the token does not expire
+	renewable   bool
+	cache       map[string]vaultSecret
+	now         func() time.Time
+}
+
+// vaultSecret is a cached secret's fields and when they must be re-fetched.
+type vaultSecret struct {
+	data    map[string]any
+	expires time.Time
+}
+
+// vaultResponse is the subset of Vault's response envelope used by VaultSource.
+type vaultResponse struct {
+	Data          map[string]any `json:"data"`
+	LeaseDuration int            `json:"lease_duration"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// FromVault creates a new Vault configuration source.
+// It validates the key mappings and, when AppRole credentials are given,
+// logs in immediately so that bad credentials are reported at startup.
+// Returns an error if the address is missing, no auth method is configured,
+// a key mapping is malformed, or the AppRole login fails.
+func FromVault(cfg VaultConfig) (*VaultSource, error) {
+	if cfg.Address == "" {
+		return nil, errors.New("vault address is required")
+	}
+	if cfg.Token == "" && cfg.AppRole == nil {
+		return nil, errors.New("vault token or AppRole credentials are required")
+	}
+	for key, ref := range cfg.Keys {
+		if _, _, err := splitVaultRef(ref); err != nil {
+			return nil, fmt.Errorf("invalid reference for %s: %w", key, err)
+		}
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = DefaultVaultCacheTTL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultVaultTimeout
+	}
+	cfg.Address = strings.TrimRight(cfg.Address, "/")
+
+	s := &VaultSource{
+		cfg:    cfg,
+		client: cfg.HTTPClient,
+		token:  cfg.Token,
+		cache:  make(map[string]vaultSecret),
+		now:    time.Now,
+	}
+	if s.client == nil {
+		s.client = http.DefaultClient
+	}
+	if cfg.AppRole != nil {
+		if err := s.login(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// splitVaultRef splits a "<path>#<field>" reference into its path and field.
+func splitVaultRef(ref string) (string, string, error) {
+	path, field, found := strings.Cut(ref, "#")
+	if !found || path == "" || field == "" {
+		return "", "", fmt.Errorf("expected <path>#<field>, got %q", ref)
+	}
+	return strings.Trim(path, "/"), field, nil
+}
+
+// Name returns the name of this source.
+func (s *VaultSource) Name() string {
+	return fmt.Sprintf("vault:%s", s.cfg.Address)
+}
+
+// GetValue retrieves the secret field mapped to key. Keys without a mapping,
+// missing secrets, and missing fields are reported as not found.
+func (s *VaultSource) GetValue(key string) (string, bool, error) {
+	ref, ok := s.cfg.Keys[key]
+	if !ok {
+		return "", false, nil
+	}
+	path, field, _ := splitVaultRef(ref)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := s.secret(path)
+	if err != nil {
+		return "", false, err
+	}
+	if data == nil {
+		return "", false, nil
+	}
+	value, found := data[field]
+	if !found || value == nil {
+		return "", false, nil
+	}
+	if str, ok := value.(string); ok {
+		return str, true, nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", false, fmt.Errorf("error encoding %s: %w", ref, err)
+	}
+	return string(encoded), true, nil
+}
+
+// IsSecret reports whether key is mapped to a Vault secret. Every value read
+// from Vault is a secret, so the loader masks it in logs and errors.
+func (s *VaultSource) IsSecret(key string) bool {
+	_, ok := s.cfg.Keys[key]
+	return ok
+}
+
+// secret returns the fields of the secret at path, from the cache when it is
+// still valid. Returns nil data if the secret does not exist.
+// The caller must hold s.mu.
+func (s *VaultSource) secret(path string) (map[string]any, error) {
+	if cached, ok := s.cache[path]; ok && s.now().Before(cached.expires) {
+		return cached.data, nil
+	}
+
+	if err := s.ensureToken(); err != nil {
+		return nil, err
+	}
+	resp, status, err := s.do(http.MethodGet, "/v1/"+path, nil)
+	if err == nil && status == http.StatusForbidden && s.cfg.AppRole != nil {
+		// the token may have been revoked; log in again and retry once
+		if err = s.login(); err == nil {
+			resp, status, err = s.do(http.MethodGet, "/v1/"+path, nil)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+	if status != http.StatusOK {
+		return nil, vaultError(path, status, resp)
+	}
+
+	data := resp.Data
+	// KV v2 nests the secret's fields under data.data next to data.metadata
+	if nested, ok := data["data"].(map[string]any); ok {
+		if _, hasMetadata := data["metadata"]; hasMetadata {
+			data = nested
+		}
+	}
+	ttl := s.cfg.CacheTTL
+	if resp.LeaseDuration > 0 {
+		ttl = time.Duration(resp.LeaseDuration) * time.Second
+	}
+	s.cache[path] = vaultSecret{data: data, expires: s.now().Add(ttl)}
+	return data, nil
+}
+
+// ensureToken renews the current token if it is about to expire, logging in
+// again through AppRole when the token cannot be renewed.
+// The caller must hold s.mu.
+func (s *VaultSource) ensureToken() error {
+	if s.tokenExpiry.IsZero() || s.now().Before(s.tokenExpiry.Add(-vaultRenewMargin)) {
+		return nil
+	}
+	if s.renewable {
+		resp, status, err := s.do(http.MethodPost, "/v1/auth/token/renew-self", nil)
+		if err == nil && status == http.StatusOK && resp.Auth != nil {
+			s.setToken(resp.Auth.ClientToken, resp.Auth.LeaseDuration, resp.Auth.Renewable)
+			return nil
+		}
+	}
+	if s.cfg.AppRole == nil {
+		return errors.New("vault token expired and cannot be renewed")
+	}
+	return s.login()
+}
+
+// login authenticates with AppRole and stores the issued token.
+// The caller must hold s.mu, except during construction.
+func (s *VaultSource) login() error {
+	mount := s.cfg.AppRole.MountPath
+	if mount == "" {
+		mount = defaultAppRoleMount
+	}
+	body := map[string]string{
+		"role_id":   s.cfg.AppRole.RoleID,
+		"secret_id": s.cfg.AppRole.SecretID,
+	}
+	s.token = ""
+	resp, status, err := s.do(http.MethodPost, fmt.Sprintf("/v1/auth/%s/login", strings.Trim(mount, "/")), body)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK || resp.Auth == nil {
+		return vaultError("approle login", status, resp)
+	}
+	s.setToken(resp.Auth.ClientToken, resp.Auth.LeaseDuration, resp.Auth.Renewable)
+	return nil
+}
+
+// setToken stores a token and computes its expiry from its lease duration.
+func (s *VaultSource) setToken(token string, leaseDuration int, renewable bool) {
+	s.token = token
+	s.renewable = renewable
+	s.tokenExpiry = time.Time{}
+	if leaseDuration > 0 {
+		s.tokenExpiry = s.now().Add(time.Duration(leaseDuration) * time.Second)
+	}
+}
+
+// do sends a request to the Vault API and decodes the response envelope.
+// The request is bounded by Timeout, since callers hold the source's lock.
+func (s *VaultSource) do(method, path string, body any) (*vaultResponse, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.Timeout)
+	defer cancel()
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&reqBody).Encode(body); err != nil {
+			return nil, 0, fmt.Errorf("error encoding vault request: %w", err)
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.cfg.Address+path, &reqBody)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating vault request: %w", err)
+	}
+	if s.token != "" {
+		req.Header.Set("X-Vault-Token", s.token)
+	}
+	if s.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.cfg.Namespace)
+	}
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error requesting %s from vault: %w", path, err)
+	}
+	defer res.Body.Close()
+
+	var resp vaultResponse
+	if err := json.NewDecoder(res.Body).Decode(&resp); err != nil && res.StatusCode == http.StatusOK {
+		return nil, res.StatusCode, fmt.Errorf("error decoding vault response for %s: %w", path, err)
+	}
+	return &resp, res.StatusCode, nil
+}
+
+// vaultError builds an error from a failed Vault response.
+func vaultError(what string, status int, resp *vaultResponse) error {
+	if resp != nil && len(resp.Errors) > 0 {
+		return fmt.Errorf("vault error for %s (status %d): %s", what, status, strings.Join(resp.Errors, "; "))
+	}
+	return fmt.Errorf("vault error for %s (status %d)", what, status)
+}
@@ -0,0 +1,300 @@
+package sources
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeVault is an in-process stand-in for the parts of the Vault HTTP API
+// used by VaultSource.
+type fakeVault struct {
+	mu         sync.Mutex
+	secrets    map[string]map[string]any // KV v2 secrets keyed by API path
+	tokens     map[string]bool           // currently valid tokens
+	logins     int
+	renewals   int
+	reads      int
+	tokenLease int
+	nextToken  int
+}
+
+func newFakeVault() *fakeVault {
+	return &fakeVault{
+		secrets: map[string]map[string]any{
+			"/v1/secret/data/app": {"db_password": "s3cret", "db_port": float64(5432)},
+		},
+		tokens:     map[string]bool{"root": true},
+		tokenLease: 3600,
+	}
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/v1/auth/approle/login":
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body["role_id"] != "role" || body["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["invalid role or secret ID"]}`))
+			return
+		}
+		f.logins++
+		f.nextToken++
+		token := fmt.Sprintf("token-%d", f.nextToken)
+		f.tokens[token] = true
+		writeJSON(w, map[string]any{"auth": map[string]any{
+			"client_token": token, "lease_duration": f.tokenLease, "renewable": true,
+		}})
+	case "/v1/auth/token/renew-self":
+		if !f.tokens[r.Header.Get("X-Vault-Token")] {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		f.renewals++
+		writeJSON(w, map[string]any{"auth": map[string]any{
+			"client_token": r.Header.Get("X-Vault-Token"), "lease_duration": f.tokenLease, "renewable": true,
+		}})
+	default:
+		if !f.tokens[r.Header.Get("X-Vault-Token")] {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		secret, ok := f.secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+			return
+		}
+		f.reads++
+		writeJSON(w, map[string]any{
+			"lease_duration": 0,
+			"data":           map[string]any{"data": secret, "metadata": map[string]any{"version": 1}},
+		})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestFromVault(t *testing.T) {
+	t.Run("error without address", func(t *testing.T) {
+		source, err := FromVault(VaultConfig{Token: "root"})
+		if err == nil {
+			t.Error("expected error without address")
+		}
+		if source != nil {
+			t.Error("expected source to be nil on error")
+		}
+	})
+
+	t.Run("error without auth", func(t *testing.T) {
+		if _, err := FromVault(VaultConfig{Address: "http://vault"}); err == nil {
+			t.Error("expected error without token or AppRole")
+		}
+	})
+
+	t.Run("error with malformed reference", func(t *testing.T) {
+		_, err := FromVault(VaultConfig{
+			Address: "http://vault",
+			Token:   "root",
+			Keys:    map[string]string{"DB_PASSWORD": "secret/data/app"},
+		})
+		if err == nil {
+			t.Error("expected error for reference without field")
+		}
+	})
+
+	t.Run("error with bad AppRole credentials", func(t *testing.T) {
+		server := httptest.NewServer(newFakeVault())
+		defer server.Close()
+
+		_, err := FromVault(VaultConfig{
+			Address: server.URL,
+			AppRole: &VaultAppRole{RoleID: "role", SecretID: "wrong"},
+		})
+		if err == nil {
+			t.Error("expected error for bad AppRole credentials")
+		}
+	})
+}
+
+func TestVaultSource_GetValue(t *testing.T) {
+	fake := newFakeVault()
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	source, err := FromVault(VaultConfig{
+		Address: server.URL,
+		Token:   "root",
+		Keys: map[string]string{
+			"DB_PASSWORD": "secret/data/app#db_password",
+			"DB_PORT":     "secret/data/app#db_port",
+			"DB_USER":     "secret/data/app#db_user",
+			"API_KEY":     "secret/data/missing#key",
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+
+	t.Run("read mapped field", func(t *testing.T) {
+		val, found, err := source.GetValue("DB_PASSWORD")
+		if err != nil {
+			t.Errorf("expected no error, got: %s", err)
+		}
+		if !found || val != "s3cret" {
+			t.Errorf("expected 's3cret', got: %q (found=%t)", val, found)
+		}
+
+		val, found, _ = source.GetValue("DB_PORT")
+		if !found || val != "5432" {
+			t.Errorf("expected '5432', got: %q (found=%t)", val, found)
+		}
+	})
+
+	t.Run("mapped keys are secret", func(t *testing.T) {
+		if !source.IsSecret("DB_PASSWORD") || !source.IsSecret("DB_PORT") {
+			t.Error("expected mapped keys to be secret")
+		}
+		if source.IsSecret("UNMAPPED") {
+			t.Error("expected unmapped key not to be secret")
+		}
+	})
+
+	t.Run("cache secret between lookups", func(t *testing.T) {
+		if fake.reads != 1 {
+			t.Errorf("expected secret to be read once, got: %d", fake.reads)
+		}
+	})
+
+	t.Run("missing field, secret, and mapping are not found", func(t *testing.T) {
+		for _, key := range []string{"DB_USER", "API_KEY", "UNMAPPED"} {
+			_, found, err := source.GetValue(key)
+			if err != nil {
+				t.Errorf("expected no error for %s, got: %s", key, err)
+			}
+			if found {
+				t.Errorf("expected %s not to be found", key)
+			}
+		}
+	})
+
+	t.Run("re-fetch after cache expiry", func(t *testing.T) {
+		reads := fake.reads
+		source.now = func() time.Time { return time.Now().Add(DefaultVaultCacheTTL + time.Second) }
+		defer func() { source.now = time.Now }()
+
+		if _, _, err := source.GetValue("DB_PASSWORD"); err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if fake.reads != reads+1 {
+			t.Errorf("expected secret to be re-fetched, got %d reads", fake.reads)
+		}
+	})
+
+	t.Run("error on permission denied", func(t *testing.T) {
+		denied, _ := FromVault(VaultConfig{
+			Address: server.URL,
+			Token:   "bad",
+			Keys:    map[string]string{"DB_PASSWORD": "secret/data/app#db_password"},
+		})
+		_, found, err := denied.GetValue("DB_PASSWORD")
+		if err == nil {
+			t.Error("expected error for denied request")
+		}
+		if found {
+			t.Error("expected value not to be found")
+		}
+	})
+
+	t.Run("error when vault does not respond in time", func(t *testing.T) {
+		hung := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		}))
+		defer hung.Close()
+
+		slow, _ := FromVault(VaultConfig{
+			Address: hung.URL,
+			Token:   "root",
+			Keys:    map[string]string{"DB_PASSWORD": "secret/data/app#db_password"},
+			Timeout: 50 * time.Millisecond,
+		})
+		start := time.Now()
+		if _, _, err := slow.GetValue("DB_PASSWORD"); err == nil {
+			t.Error("expected error for timed out request")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("expected request to time out after 50ms, took: %s", elapsed)
+		}
+	})
+}
+
+func TestVaultSource_AppRole(t *testing.T) {
+	fake := newFakeVault()
+	fake.tokenLease = 60
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	source, err := FromVault(VaultConfig{
+		Address: server.URL,
+		AppRole: &VaultAppRole{RoleID: "role", SecretID: "secret"},
+		Keys:    map[string]string{"DB_PASSWORD": "secret/data/app#db_password"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+	if fake.logins != 1 {
+		t.Errorf("expected login at construction, got: %d logins", fake.logins)
+	}
+
+	t.Run("renew token before expiry", func(t *testing.T) {
+		source.now = func() time.Time { return time.Now().Add(55 * time.Second) }
+		defer func() { source.now = time.Now }()
+
+		val, found, err := source.GetValue("DB_PASSWORD")
+		if err != nil || !found || val != "s3cret" {
+			t.Fatalf("expected 's3cret', got: %q (found=%t, err=%v)", val, found, err)
+		}
+		if fake.renewals != 1 {
+			t.Errorf("expected token to be renewed, got: %d renewals", fake.renewals)
+		}
+	})
+
+	t.Run("log in again when token is revoked", func(t *testing.T) {
+		fake.mu.Lock()
+		fake.tokens = map[string]bool{}
+		fake.mu.Unlock()
+		source.mu.Lock()
+		source.cache = map[string]vaultSecret{}
+		source.mu.Unlock()
+
+		val, found, err := source.GetValue("DB_PASSWORD")
+		if err != nil || !found || val != "s3cret" {
+			t.Fatalf("expected 's3cret', got: %q (found=%t, err=%v)", val, found, err)
+		}
+		if fake.logins != 2 {
+			t.Errorf("expected a second login, got: %d logins", fake.logins)
+		}
+	})
+}
+
+func TestVaultSource_Name(t *testing.T) {
+	source, err := FromVault(VaultConfig{Address: "http://vault:8200/", Token: "root"})
+	if err != nil {
+		t.Fatalf("failed to create source: %s", err)
+	}
+	if name := source.Name(); name != "vault:http://vault:8200" {
+		t.Errorf("expected Name() to return 'vault:http://vault:8200', got: %s", name)
+	}
+}
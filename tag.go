@@ -0,0 +1,106 @@
+package configly
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// splitTag splits a struct tag into its key and options at commas.
+// An option value may be wrapped in single quotes to include commas or
+// surrounding spaces, e.g. default='a, b' or layout='Mon, 02 Jan 2006';
+// a quote inside a quoted value is written twice. Quotes are removed from the
+// returned options, so an empty quoted default yields "default=". A quote is
+// only special at the start of a value: default=it's is taken literally.
+// Unquoted options are trimmed of surrounding whitespace.
+// Returns an error for an unterminated quoted value or text after a closing
+// quote.
+func splitTag(tag string) ([]string, error) {
+	var parts []string
+	var part strings.Builder
+	for i := 0; i <= len(tag); i++ {
+		if i == len(tag) || tag[i] == ',' {
+			parts = append(parts, strings.TrimSpace(part.String()))
+			part.Reset()
+			continue
+		}
+		if tag[i] != '\'' || len(parts) == 0 || !isValueStart(part.String()) {
+			part.WriteByte(tag[i])
+			continue
+		}
+
+		value, end, err := readQuoted(tag, i)
+		if err != nil {
+			return nil, err
+		}
+		next := end + 1
+		for next < len(tag) && tag[next] == ' ' {
+			next++
+		}
+		if next < len(tag) && tag[next] != ',' {
+			return nil, fmt.Errorf("unexpected %q after quoted value in tag option %s", tag[next:next+1], strings.TrimSpace(part.String()))
+		}
+		parts = append(parts, strings.TrimSpace(part.String())+value)
+		part.Reset()
+		i = next
+	}
+	return parts, nil
+}
+
+// isValueStart reports whether an option parsed up to part is about to
+// start its value, i.e. part is a name followed by its first "=".
+func isValueStart(part string) bool {
+	part = strings.TrimRight(part, " ")
+	return strings.HasSuffix(part, "=") && strings.Count(part, "=") == 1
+}
+
+// readQuoted reads the single-quoted value starting at tag[start], where two
+// quotes in a row stand for a literal quote. Returns the unquoted value and the index of the
+// closing quote.
+func readQuoted(tag string, start int) (string, int, error) {
+	var value strings.Builder
+	for i := start + 1; i < len(tag); i++ {
+		if tag[i] != '\'' {
+			value.WriteByte(tag[i])
+			continue
+		}
+		if i+1 < len(tag) && tag[i+1] == '\'' {
+			value.WriteByte('\'')
+			i++
+			continue
+		}
+		return value.String(), i, nil
+	}
+	return "", 0, fmt.Errorf("unterminated quoted value in tag %q", tag)
+}
+
+// envDependentValidators are validators whose result depends on the
+// environment rather than on the value alone. They are not applied to
+// defaults when tags are parsed, since a source may override the default.
+var envDependentValidators = []string{"file", "dir"}
+
+// checkDefault parses the tag default of a field of typ and checks it
+// against the field's constraints, so invalid defaults are reported when tags
+// are parsed rather than only when the default is used. With Interpolate,
+// defaults containing ${...} references are checked after expansion, when
+// loading.
+func (l *Loader[T]) checkDefault(opts tagOptions, typ reflect.Type) []error {
+	if !opts.hasDefault || l.interpolate && !opts.noExpand && strings.Contains(opts.defaultValue, "$") {
+		return nil
+	}
+	value := reflect.New(typ).Elem()
+	if err := l.setField(&value, opts.defaultValue, opts); err != nil {
+		return []error{fmt.Errorf("invalid default for %s: %w", opts.key, err)}
+	}
+
+	checkOpts := opts
+	checkOpts.custom = nil
+	checkOpts.validators = slices.DeleteFunc(slices.Clone(opts.validators), func(v tagValidator) bool {
+		return slices.Contains(envDependentValidators, v.name)
+	})
+	if err := l.validateField(value, checkOpts); err != nil {
+		return []error{fmt.Errorf("invalid default for %s: %w", opts.key, err)}
+	}
+	return nil
+}
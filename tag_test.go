@@ -0,0 +1,273 @@
+package configly
+
+import (
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zanedma/configly/sources"
+)
+
+func TestSplitTag(t *testing.T) {
+	tests := map[string][]string{
+		"KEY":                                 {"KEY"},
+		"KEY,required, min=1 ":                {"KEY", "required", "min=1"},
+		"KEY,default='a,b',required":          {"KEY", "default=a,b", "required"},
+		"KEY,default=''":                      {"KEY", "default="},
+		"KEY,default=":                        {"KEY", "default="},
+		"KEY,default=' padded '":              {"KEY", "default= padded "},
+		"KEY,default='it''s'":                 {"KEY", "default=it's"},
+		"KEY,default=''''":                    {"KEY", "default='"},
+		"KEY,default=it's":                    {"KEY", "default=it's"},
+		"KEY,layout='Mon, 02 Jan 2006' ,x":    {"KEY", "layout=Mon, 02 Jan 2006", "x"},
+		"KEY,required_if='MODE:a,b'":          {"KEY", "required_if=MODE:a,b"},
+		"KEY,default=a='b'":                   {"KEY", "default=a='b'"},
+		"KEY,":                                {"KEY", ""},
+		"'KEY,X'":                             {"'KEY", "X'"},
+		"KEY,default='${HOST:-a,b}',noexpand": {"KEY", "default=${HOST:-a,b}", "noexpand"},
+	}
+	for tag, expected := range tests {
+		parts, err := splitTag(tag)
+		if err != nil {
+			t.Errorf("%s: expected no error, got: %s", tag, err)
+			continue
+		}
+		if !slices.Equal(parts, expected) {
+			t.Errorf("%s: expected %q, got: %q", tag, expected, parts)
+		}
+	}
+
+	invalid := map[string]string{
+		"KEY,default='a,b":   "unterminated quoted value",
+		"KEY,default='a'b,x": `unexpected "b" after quoted value in tag option default=`,
+		"KEY,default='a' b":  `unexpected "b" after quoted value`,
+		"KEY,default='a''":   "unterminated quoted value",
+	}
+	for tag, expected := range invalid {
+		_, err := splitTag(tag)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%s: expected error containing %q, got: %v", tag, expected, err)
+		}
+	}
+}
+
+func TestLoadDefaults(t *testing.T) {
+	t.Setenv("CONFIGLY_TEST_REGION", "eu-west-1")
+
+	type defaultsConfig struct {
+		Prefix  string            `configly:"PREFIX,default=''"`
+		Suffix  string            `configly:"SUFFIX,default=,maxLen=0"`
+		Origins []string          `configly:"ORIGINS,default='https://a.example.com, https://b.example.com'"`
+		Ports   []int             `configly:"PORTS,default='80,443',port"`
+		Limits  map[string]int    `configly:"LIMITS,default='eu=10,us=20',min=1"`
+		Sizes   []ByteSize        `configly:"SIZES,default='1KiB,4MiB'"`
+		Empty   []string          `configly:"EMPTY,default=''"`
+		Region  string            `configly:"REGION,default=${env:CONFIGLY_TEST_REGION}"`
+		Bucket  string            `configly:"BUCKET,default=${PREFIX}logs-${REGION}"`
+		Zone    string            `configly:"ZONE,default='${env:CONFIGLY_TEST_UNSET:-us-east-1a}'"`
+		Price   string            `configly:"PRICE,default=$$5"`
+		Raw     string            `configly:"RAW,default=${HOST},noexpand"`
+		Labels  map[string]string `configly:"LABELS"`
+		Timeout time.Duration     `configly:"TIMEOUT,default=1w"`
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		l, err := New[defaultsConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}, Interpolate: true})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		cfg, provenance, err := l.LoadWithProvenance()
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+
+		if _, ok := provenance["PREFIX"]; !ok || cfg.Prefix != "" {
+			t.Errorf("expected explicit empty default for PREFIX, got: %q (provenance %v)", cfg.Prefix, ok)
+		}
+		if _, ok := provenance["SUFFIX"]; !ok {
+			t.Error("expected explicit empty default for SUFFIX")
+		}
+		if !slices.Equal(cfg.Origins, []string{"https://a.example.com", "https://b.example.com"}) {
+			t.Errorf("unexpected origins: %q", cfg.Origins)
+		}
+		if !slices.Equal(cfg.Ports, []int{80, 443}) {
+			t.Errorf("unexpected ports: %v", cfg.Ports)
+		}
+		if !reflect.DeepEqual(cfg.Limits, map[string]int{"eu": 10, "us": 20}) {
+			t.Errorf("unexpected limits: %v", cfg.Limits)
+		}
+		if !slices.Equal(cfg.Sizes, []ByteSize{KiB, 4 * MiB}) {
+			t.Errorf("unexpected sizes: %v", cfg.Sizes)
+		}
+		if cfg.Empty == nil || len(cfg.Empty) != 0 {
+			t.Errorf("expected empty non-nil slice, got: %#v", cfg.Empty)
+		}
+		if cfg.Region != "eu-west-1" || cfg.Bucket != "logs-eu-west-1" || cfg.Zone != "us-east-1a" {
+			t.Errorf("unexpected expanded defaults: %s, %s, %s", cfg.Region, cfg.Bucket, cfg.Zone)
+		}
+		if cfg.Price != "$5" || cfg.Raw != "${HOST}" {
+			t.Errorf("unexpected escaped defaults: %s, %s", cfg.Price, cfg.Raw)
+		}
+		if cfg.Labels != nil {
+			t.Errorf("expected nil labels without value or default, got: %v", cfg.Labels)
+		}
+		if cfg.Timeout != week {
+			t.Errorf("expected timeout 1w, got: %s", cfg.Timeout)
+		}
+	})
+
+	t.Run("source values", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{
+			"PREFIX": "prod-",
+			"PORTS":  "8080",
+			"LABELS": "team=core, tier = gold",
+			"LIMITS": "",
+		})
+		l, _ := New[defaultsConfig](LoaderConfig{Sources: []sources.Source{source}, Interpolate: true})
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if cfg.Bucket != "prod-logs-eu-west-1" {
+			t.Errorf("expected default to reference loaded value, got: %s", cfg.Bucket)
+		}
+		if !slices.Equal(cfg.Ports, []int{8080}) {
+			t.Errorf("unexpected ports: %v", cfg.Ports)
+		}
+		if !reflect.DeepEqual(cfg.Labels, map[string]string{"team": "core", "tier": "gold"}) {
+			t.Errorf("unexpected labels: %v", cfg.Labels)
+		}
+		if cfg.Limits == nil || len(cfg.Limits) != 0 {
+			t.Errorf("expected empty limits, got: %v", cfg.Limits)
+		}
+	})
+
+	t.Run("defaults are not expanded without Interpolate", func(t *testing.T) {
+		type literalConfig struct {
+			Dir      string `configly:"DIR,default=${HOME_DIR}/x"`
+			Password string `configly:"PASSWORD,default=pa$$word"`
+			Port     string `configly:"PORT,default=${env:PORT}"`
+		}
+		l, err := New[literalConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if cfg.Dir != "${HOME_DIR}/x" || cfg.Password != "pa$$word" || cfg.Port != "${env:PORT}" {
+			t.Errorf("expected literal defaults, got: %s, %s, %s", cfg.Dir, cfg.Password, cfg.Port)
+		}
+
+		type refConfig struct {
+			Ref int `configly:"REF,default=${PORT}"`
+		}
+		_, err = New[refConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}})
+		if err == nil || !strings.Contains(err.Error(), "invalid default for REF") {
+			t.Errorf("expected literal default to be checked, got: %v", err)
+		}
+	})
+
+	t.Run("invalid elements", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{"PORTS": "80,0", "LIMITS": "eu=0", "LABELS": "team"})
+		l, _ := New[defaultsConfig](LoaderConfig{Sources: []sources.Source{source}})
+		_, err := l.Load()
+		if err == nil {
+			t.Fatal("expected errors")
+		}
+		for _, expected := range []string{
+			"element 1: invalid port 0",
+			"map key eu: integer value 0 is less than minimum 1",
+			`invalid map entry "team": expected key=value`,
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %s", expected, err)
+			}
+		}
+	})
+
+	t.Run("invalid defaults are reported when tags are parsed", func(t *testing.T) {
+		type invalidConfig struct {
+			Port    int      `configly:"PORT,default=http"`
+			Retries int      `configly:"RETRIES,default=,min=0"`
+			Name    string   `configly:"NAME,default=ab,minLen=3"`
+			Hosts   []string `configly:"HOSTS,default='a,-b',hostname"`
+			Cert    string   `configly:"CERT,default=/does/not/exist,file"`
+			Ref     int      `configly:"REF,default=${PORT}"`
+			Quote   string   `configly:"QUOTE,default='open"`
+		}
+		_, err := New[invalidConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}, Interpolate: true})
+		if err == nil {
+			t.Fatal("expected parse errors")
+		}
+		for _, expected := range []string{
+			"invalid default for PORT: invalid integer",
+			"invalid default for RETRIES: invalid integer",
+			"invalid default for NAME: string length 2 less than minimum 3",
+			"invalid default for HOSTS: element 1: invalid hostname",
+			"unterminated quoted value",
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %s", expected, err)
+			}
+		}
+		for _, unexpected := range []string{"CERT", "REF"} {
+			if strings.Contains(err.Error(), unexpected) {
+				t.Errorf("expected no parse error for %s, got: %s", unexpected, err)
+			}
+		}
+	})
+
+	t.Run("unset environment variable", func(t *testing.T) {
+		type envConfig struct {
+			Token string `configly:"TOKEN,default=${env:CONFIGLY_TEST_UNSET}"`
+		}
+		os.Unsetenv("CONFIGLY_TEST_UNSET")
+		l, _ := New[envConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}, Interpolate: true})
+		_, err := l.Load()
+		if err == nil || !strings.Contains(err.Error(), "environment variable CONFIGLY_TEST_UNSET is not set") {
+			t.Errorf("expected unset variable error, got: %v", err)
+		}
+	})
+}
+
+func TestFormatCollections(t *testing.T) {
+	type collectionsConfig struct {
+		Hosts  []string          `configly:"HOSTS"`
+		Limits map[string]int    `configly:"LIMITS"`
+		Labels map[string]string `configly:"LABELS"`
+	}
+
+	defaults := &collectionsConfig{
+		Hosts:  []string{"a", "b"},
+		Limits: map[string]int{"us": 20, "eu": 10},
+	}
+	l, err := New[collectionsConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}, Defaults: defaults})
+	if err != nil {
+		t.Fatalf("expected no error, got: %s", err)
+	}
+	cfg, err := l.Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %s", err)
+	}
+	if !reflect.DeepEqual(cfg.Hosts, defaults.Hosts) || !reflect.DeepEqual(cfg.Limits, defaults.Limits) {
+		t.Errorf("expected defaults to round-trip, got: %v, %v", cfg.Hosts, cfg.Limits)
+	}
+
+	str, err := formatField(reflect.ValueOf(defaults.Limits), tagOptions{})
+	if err != nil || str != "eu=10,us=20" {
+		t.Errorf("expected eu=10,us=20, got: %s, %v", str, err)
+	}
+
+	_, err = New[collectionsConfig](LoaderConfig{
+		Sources:  []sources.Source{sources.FromMap(nil)},
+		Defaults: &collectionsConfig{Labels: map[string]string{"k": "a,b"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), `value "a,b" cannot be written as a list item`) {
+		t.Errorf("expected list item error, got: %v", err)
+	}
+}
@@ -0,0 +1,364 @@
+package configly
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// maxHostnameLength is the maximum length of a hostname (RFC 1035).
+	maxHostnameLength = 253
+	// maxHostnameLabelLength is the maximum length of a hostname label.
+	maxHostnameLabelLength = 63
+	// minPort and maxPort bound valid TCP/UDP port numbers.
+	minPort = 1
+	maxPort = 65535
+)
+
+// ValidatorFunc is a custom validator registered in LoaderConfig.Validators.
+// It is called with the loaded field value (e.g. a string for string fields,
+// an int for int fields) and the tag option's value after "=", if any, and
+// returns an error if the value is invalid.
+type ValidatorFunc func(value any, param string) error
+
+// tagValidator is a validator option from a struct tag, such as url=https,
+// hostname, or a custom validator.
+type tagValidator struct {
+	name  string // Option name, e.g. url
+	param string // Option value after "=", if any
+}
+
+// stringValidators are the built-in validators for string fields, keyed by
+// tag option. The param is the option's value after "=", if any.
+var stringValidators = map[string]func(str, param string) error{
+	"url":      validateURL,
+	"hostname": validateHostname,
+	"ip":       validateIP,
+	"ipv4":     validateIPv4,
+	"ipv6":     validateIPv6,
+	"cidr":     validateCIDR,
+	"email":    validateEmail,
+	"port":     validatePortString,
+	"file":     validateFile,
+	"dir":      validateDir,
+	"absPath":  validateAbsPath,
+}
+
+// integerValidators are the built-in validators for integer fields.
+var integerValidators = map[string]bool{
+	"port": true,
+}
+
+// parameterizedValidators are the built-in validators that accept a param.
+var parameterizedValidators = map[string]bool{
+	"url": true,
+}
+
+// builtinOptions are the tag options that are not validators.
+var builtinOptions = []string{
+	"required", "noexpand", "default", "min", "max", "minLen", "maxLen",
+	"required_if", "required_with", "excluded_with", "bytes", "layout",
+	"secret", "reloadable", "restart", "pattern", "oneof",
+}
+
+// isBuiltinOption reports whether name is a built-in tag option, and thus
+// cannot be registered as a custom validator.
+func isBuiltinOption(name string) bool {
+	_, isValidator := stringValidators[name]
+	_, isComparison := comparisonOps[name]
+	return isValidator || isComparison || slices.Contains(builtinOptions, name)
+}
+
+// checkCustomValidators checks the names and functions of custom validators
+// registered in LoaderConfig.Validators.
+func checkCustomValidators(validators map[string]ValidatorFunc) error {
+	var validatorErrors []error
+	for _, name := range slices.Sorted(maps.Keys(validators)) {
+		switch {
+		case name == "" || strings.ContainsAny(name, "=,") || strings.TrimSpace(name) != name:
+			validatorErrors = append(validatorErrors, fmt.Errorf("invalid validator name %q", name))
+		case isBuiltinOption(name):
+			validatorErrors = append(validatorErrors, fmt.Errorf("invalid validator name %q: conflicts with built-in tag option", name))
+		case validators[name] == nil:
+			validatorErrors = append(validatorErrors, fmt.Errorf("invalid validator %s: function is nil", name))
+		}
+	}
+	return errors.Join(validatorErrors...)
+}
+
+// isValidatorOption reports whether a tag option part names a built-in
+// validator.
+func isValidatorOption(part string) bool {
+	name, _, _ := strings.Cut(part, "=")
+	_, ok := stringValidators[name]
+	return ok
+}
+
+// parseValidator parses a built-in validator option from a tag part.
+// Returns an error if a value is given to a validator that takes none.
+func parseValidator(part string) (tagValidator, error) {
+	name, param, hasParam := strings.Cut(part, "=")
+	if hasParam && !parameterizedValidators[name] {
+		return tagValidator{}, fmt.Errorf("%s does not take a value", name)
+	}
+	return tagValidator{name: name, param: param}, nil
+}
+
+// hasValidator reports whether opts includes the validator name.
+func hasValidator(opts tagOptions, name string) bool {
+	return slices.ContainsFunc(opts.validators, func(v tagValidator) bool {
+		return v.name == name
+	})
+}
+
+// checkValidatorKinds reports validators that do not apply to a field of typ.
+func checkValidatorKinds(opts tagOptions, typ reflect.Type) []error {
+	var kindErrors []error
+	for _, v := range opts.validators {
+		supported := false
+		switch typ.Kind() {
+		case reflect.String:
+			_, supported = stringValidators[v.name]
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			supported = integerValidators[v.name] && typ != durationType
+		}
+		if !supported {
+			kindErrors = append(kindErrors, fmt.Errorf("invalid tag for %s: validator %s is not supported for type %s", opts.key, v.name, typ))
+		}
+	}
+	return kindErrors
+}
+
+// checkValueOptions reports pattern and oneof options on fields of a type
+// they do not apply to: pattern applies to strings, and oneof to strings and
+// integers, whose oneof values must be integers.
+func checkValueOptions(opts tagOptions, typ reflect.Type) []error {
+	var optionErrors []error
+	if opts.pattern != nil && typ.Kind() != reflect.String {
+		optionErrors = append(optionErrors, fmt.Errorf("invalid tag for %s: pattern is not supported for type %s", opts.key, typ))
+	}
+	if opts.oneOf == nil {
+		return optionErrors
+	}
+	switch typ.Kind() {
+	case reflect.String:
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if typ == durationType || opts.bytes {
+			optionErrors = append(optionErrors, fmt.Errorf("invalid tag for %s: oneof is not supported for type %s", opts.key, typ))
+			break
+		}
+		for _, value := range opts.oneOf {
+			if _, err := parseInteger(value, 64); err != nil {
+				optionErrors = append(optionErrors, fmt.Errorf("invalid tag for %s: oneof value %q is not an integer", opts.key, value))
+			}
+		}
+	default:
+		optionErrors = append(optionErrors, fmt.Errorf("invalid tag for %s: oneof is not supported for type %s", opts.key, typ))
+	}
+	return optionErrors
+}
+
+// checkOneOf checks that a string or integer field holds one of the values
+// of its oneof option, if any. Integers are compared by value, so oneof=0x10
+// allows 16.
+func checkOneOf(field reflect.Value, opts tagOptions) error {
+	if opts.oneOf == nil {
+		return nil
+	}
+	for _, allowed := range opts.oneOf {
+		switch field.Kind() {
+		case reflect.String:
+			if field.String() == allowed {
+				return nil
+			}
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if val, err := parseInteger(allowed, 64); err == nil && val == field.Int() {
+				return nil
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if val, err := parseInteger(allowed, 64); err == nil && val >= 0 && uint64(val) == field.Uint() {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("value %v is not one of: %s", field.Interface(), strings.Join(opts.oneOf, ", "))
+}
+
+// validateURL checks that str is an absolute URL with a host. The optional
+// param lists the allowed schemes separated by spaces, e.g. "http https".
+func validateURL(str, param string) error {
+	u, err := url.Parse(str)
+	if err != nil {
+		return fmt.Errorf("invalid url %q: %w", str, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid url %q: must be absolute with a scheme and host", str)
+	}
+	if schemes := strings.Fields(param); len(schemes) > 0 && !slices.Contains(schemes, strings.ToLower(u.Scheme)) {
+		return fmt.Errorf("invalid url %q: scheme %s is not allowed (allowed: %s)", str, u.Scheme, strings.Join(schemes, ", "))
+	}
+	return nil
+}
+
+// validateHostname checks that str is a valid DNS hostname (RFC 1123): dot
+// separated labels of letters, digits, and hyphens, not starting or ending
+// with a hyphen. A single trailing dot is allowed.
+func validateHostname(str, _ string) error {
+	name := strings.TrimSuffix(str, ".")
+	if name == "" || len(name) > maxHostnameLength {
+		return fmt.Errorf("invalid hostname %q: must be 1 to %d characters", str, maxHostnameLength)
+	}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > maxHostnameLabelLength {
+			return fmt.Errorf("invalid hostname %q: labels must be 1 to %d characters", str, maxHostnameLabelLength)
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return fmt.Errorf("invalid hostname %q: labels must not start or end with a hyphen", str)
+		}
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-') {
+				return fmt.Errorf("invalid hostname %q: invalid character %q", str, c)
+			}
+		}
+	}
+	return nil
+}
+
+// validateIP checks that str is an IPv4 or IPv6 address.
+func validateIP(str, _ string) error {
+	if _, err := netip.ParseAddr(str); err != nil {
+		return fmt.Errorf("invalid ip address %q", str)
+	}
+	return nil
+}
+
+// validateIPv4 checks that str is an IPv4 address.
+func validateIPv4(str, _ string) error {
+	addr, err := netip.ParseAddr(str)
+	if err != nil || !addr.Is4() {
+		return fmt.Errorf("invalid ipv4 address %q", str)
+	}
+	return nil
+}
+
+// validateIPv6 checks that str is an IPv6 address.
+func validateIPv6(str, _ string) error {
+	addr, err := netip.ParseAddr(str)
+	if err != nil || !addr.Is6() {
+		return fmt.Errorf("invalid ipv6 address %q", str)
+	}
+	return nil
+}
+
+// validateCIDR checks that str is an IP network in CIDR notation.
+func validateCIDR(str, _ string) error {
+	if _, err := netip.ParsePrefix(str); err != nil {
+		return fmt.Errorf("invalid cidr %q: %w", str, err)
+	}
+	return nil
+}
+
+// validateEmail checks that str is a bare email address, without a display
+// name or angle brackets.
+func validateEmail(str, _ string) error {
+	addr, err := mail.ParseAddress(str)
+	if err != nil {
+		return fmt.Errorf("invalid email address %q: %w", str, err)
+	}
+	if addr.Address != str || addr.Name != "" {
+		return fmt.Errorf("invalid email address %q: must be a bare address", str)
+	}
+	if _, domain, _ := strings.Cut(str, "@"); !strings.Contains(domain, ".") {
+		return fmt.Errorf("invalid email address %q: domain must contain a dot", str)
+	}
+	return nil
+}
+
+// validatePortString checks that str is a port number from 1 to 65535.
+func validatePortString(str, _ string) error {
+	port, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid port %q: must be a number", str)
+	}
+	return validatePort(port)
+}
+
+// validatePort checks that port is from 1 to 65535.
+func validatePort(port uint64) error {
+	if port < minPort || port > maxPort {
+		return fmt.Errorf("invalid port %d: must be between %d and %d", port, minPort, maxPort)
+	}
+	return nil
+}
+
+// validateFile checks that str names an existing, readable regular file.
+func validateFile(str, _ string) error {
+	info, err := os.Stat(str)
+	if err != nil {
+		return fmt.Errorf("invalid file %q: %w", str, pathError(err))
+	}
+	if info.IsDir() {
+		return fmt.Errorf("invalid file %q: is a directory", str)
+	}
+	f, err := os.Open(str)
+	if err != nil {
+		return fmt.Errorf("invalid file %q: %w", str, pathError(err))
+	}
+	return f.Close()
+}
+
+// validateDir checks that str names an existing, readable directory.
+func validateDir(str, _ string) error {
+	info, err := os.Stat(str)
+	if err != nil {
+		return fmt.Errorf("invalid directory %q: %w", str, pathError(err))
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid directory %q: not a directory", str)
+	}
+	f, err := os.Open(str)
+	if err != nil {
+		return fmt.Errorf("invalid directory %q: %w", str, pathError(err))
+	}
+	defer f.Close()
+	if _, err := f.Readdirnames(1); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid directory %q: %w", str, pathError(err))
+	}
+	return nil
+}
+
+// validateAbsPath checks that str is an absolute path.
+func validateAbsPath(str, _ string) error {
+	if !filepath.IsAbs(str) {
+		return fmt.Errorf("invalid path %q: must be absolute", str)
+	}
+	return nil
+}
+
+// pathError describes a file system error without repeating the path.
+func pathError(err error) error {
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return errors.New("does not exist")
+	case errors.Is(err, os.ErrPermission):
+		return errors.New("permission denied")
+	}
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return err
+}
@@ -0,0 +1,367 @@
+package configly
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zanedma/configly/sources"
+)
+
+func TestStringValidators(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "cert.pem")
+	if err := os.WriteFile(file, []byte("cert"), 0644); err != nil {
+		t.Fatalf("failed to create test file: %s", err)
+	}
+
+	tests := []struct {
+		validator string
+		param     string
+		valid     []string
+		invalid   map[string]string // value -> expected error fragment
+	}{
+		{
+			validator: "url",
+			param:     "http https",
+			valid:     []string{"https://example.com", "HTTP://example.com:8080/path?q=1"},
+			invalid: map[string]string{
+				"ftp://example.com": "scheme ftp is not allowed (allowed: http, https)",
+				"example.com/path":  "must be absolute",
+				"https://":          "must be absolute",
+			},
+		},
+		{
+			validator: "url",
+			valid:     []string{"postgres://db:5432/app"},
+			invalid:   map[string]string{"://missing": "invalid url"},
+		},
+		{
+			validator: "hostname",
+			valid:     []string{"localhost", "api.example.com", "example.com.", "a-b.c1"},
+			invalid: map[string]string{
+				"":                          "must be 1 to 253 characters",
+				"a..b":                      "labels must be 1 to 63 characters",
+				"-api.example.com":          "must not start or end with a hyphen",
+				"api_1.example.com":         "invalid character '_'",
+				strings.Repeat("a", 64):     "labels must be 1 to 63 characters",
+				strings.Repeat("a.", 128):   "must be 1 to 253 characters",
+				"https://api.example.com":   "invalid character ':'",
+				"api.example.com/path/more": "invalid character '/'",
+			},
+		},
+		{
+			validator: "ip",
+			valid:     []string{"10.0.0.1", "::1"},
+			invalid:   map[string]string{"10.0.0": "invalid ip address"},
+		},
+		{
+			validator: "ipv4",
+			valid:     []string{"192.168.1.1"},
+			invalid:   map[string]string{"::1": "invalid ipv4 address", "256.0.0.1": "invalid ipv4 address"},
+		},
+		{
+			validator: "ipv6",
+			valid:     []string{"2001:db8::1"},
+			invalid:   map[string]string{"10.0.0.1": "invalid ipv6 address"},
+		},
+		{
+			validator: "cidr",
+			valid:     []string{"10.0.0.0/8", "2001:db8::/32"},
+			invalid:   map[string]string{"10.0.0.1": "invalid cidr", "10.0.0.0/33": "invalid cidr"},
+		},
+		{
+			validator: "email",
+			valid:     []string{"ops@example.com"},
+			invalid: map[string]string{
+				"ops":                     "invalid email address",
+				"Ops <ops@example.com>":   "must be a bare address",
+				"ops@localhost":           "domain must contain a dot",
+				"ops@example.com, b@c.de": "invalid email address",
+			},
+		},
+		{
+			validator: "port",
+			valid:     []string{"1", "8080", "65535"},
+			invalid:   map[string]string{"0": "must be between 1 and 65535", "65536": "must be between 1 and 65535", "http": "must be a number"},
+		},
+		{
+			validator: "file",
+			valid:     []string{file},
+			invalid: map[string]string{
+				filepath.Join(dir, "missing.pem"): "does not exist",
+				dir:                               "is a directory",
+			},
+		},
+		{
+			validator: "dir",
+			valid:     []string{dir},
+			invalid: map[string]string{
+				filepath.Join(dir, "missing"): "does not exist",
+				file:                          "not a directory",
+			},
+		},
+		{
+			validator: "absPath",
+			valid:     []string{dir},
+			invalid:   map[string]string{"relative/path": "must be absolute"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.validator, func(t *testing.T) {
+			validate := stringValidators[tt.validator]
+			for _, value := range tt.valid {
+				if err := validate(value, tt.param); err != nil {
+					t.Errorf("expected %q to be valid, got: %s", value, err)
+				}
+			}
+			for value, expected := range tt.invalid {
+				err := validate(value, tt.param)
+				if err == nil || !strings.Contains(err.Error(), expected) {
+					t.Errorf("expected error containing %q for %q, got: %v", expected, value, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadValidators(t *testing.T) {
+	type serviceConfig struct {
+		Endpoint string `configly:"ENDPOINT,url=https"`
+		Port     int    `configly:"PORT,port"`
+		Admin    uint16 `configly:"ADMIN_PORT,port"`
+		Bind     string `configly:"BIND,ip"`
+	}
+
+	t.Run("valid values", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{"ENDPOINT": "https://api.example.com", "PORT": "8080", "ADMIN_PORT": "9090", "BIND": "0.0.0.0"})
+		l, _ := New[serviceConfig](LoaderConfig{Sources: []sources.Source{source}})
+		if _, err := l.Load(); err != nil {
+			t.Errorf("expected no error, got: %s", err)
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{"ENDPOINT": "http://api.example.com", "PORT": "-1", "ADMIN_PORT": "0", "BIND": "localhost"})
+		l, _ := New[serviceConfig](LoaderConfig{Sources: []sources.Source{source}})
+		_, err := l.Load()
+		if err == nil {
+			t.Fatal("expected validation errors")
+		}
+		for _, expected := range []string{"scheme http is not allowed", "invalid port -1", "invalid port 0", `invalid ip address "localhost"`} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %s", expected, err)
+			}
+		}
+	})
+
+	t.Run("invalid validator usage", func(t *testing.T) {
+		type invalidConfig struct {
+			Enabled bool   `configly:"ENABLED,hostname"`
+			Host    string `configly:"HOST,hostname=strict"`
+		}
+		_, err := New[invalidConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}})
+		if err == nil {
+			t.Fatal("expected parse errors")
+		}
+		if !strings.Contains(err.Error(), "validator hostname is not supported for type bool") {
+			t.Errorf("expected unsupported type error, got: %s", err)
+		}
+		if !strings.Contains(err.Error(), "hostname does not take a value") {
+			t.Errorf("expected unexpected value error, got: %s", err)
+		}
+	})
+}
+
+func TestValueOptions(t *testing.T) {
+	type valueConfig struct {
+		Name    string   `configly:"NAME,pattern='^[a-z]{2,}$'"`
+		Level   string   `configly:"LEVEL,default=info,oneof=debug info warn"`
+		Workers uint8    `configly:"WORKERS,oneof=1 2 0x4"`
+		Offset  int      `configly:"OFFSET,oneof=-1 0 1"`
+		Tags    []string `configly:"TAGS,pattern=^[a-z]+$"`
+	}
+
+	t.Run("valid values", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{"NAME": "api", "WORKERS": "4", "OFFSET": "-1", "TAGS": "a,b"})
+		l, _ := New[valueConfig](LoaderConfig{Sources: []sources.Source{source}})
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if cfg.Level != "info" || cfg.Workers != 4 {
+			t.Errorf("expected LEVEL info and WORKERS 4, got: %q %d", cfg.Level, cfg.Workers)
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{"NAME": "a1", "LEVEL": "trace", "WORKERS": "3", "OFFSET": "2", "TAGS": "a,B"})
+		l, _ := New[valueConfig](LoaderConfig{Sources: []sources.Source{source}})
+		_, err := l.Load()
+		if err == nil {
+			t.Fatal("expected validation errors")
+		}
+		for _, expected := range []string{
+			`value "a1" does not match pattern ^[a-z]{2,}$`,
+			"value trace is not one of: debug, info, warn",
+			"value 3 is not one of: 1, 2, 0x4",
+			"value 2 is not one of: -1, 0, 1",
+			`element 1: value "B" does not match pattern`,
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %s", expected, err)
+			}
+		}
+	})
+
+	t.Run("invalid usage", func(t *testing.T) {
+		type invalidConfig struct {
+			Name    string        `configly:"NAME,pattern=[a-"`
+			Port    int           `configly:"PORT,pattern=^8"`
+			Debug   bool          `configly:"DEBUG,oneof=true"`
+			Workers int           `configly:"WORKERS,oneof=one two"`
+			Timeout time.Duration `configly:"TIMEOUT,oneof=1s"`
+			Level   string        `configly:"LEVEL,default=trace,oneof=debug info"`
+		}
+		_, err := New[invalidConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}})
+		if err == nil {
+			t.Fatal("expected parse errors")
+		}
+		for _, expected := range []string{
+			"invalid pattern",
+			"pattern is not supported for type int",
+			"oneof is not supported for type bool",
+			`oneof value "one" is not an integer`,
+			"oneof is not supported for type time.Duration",
+			"invalid default for LEVEL: value trace is not one of: debug, info",
+		} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %s", expected, err)
+			}
+		}
+	})
+}
+
+func TestCustomValidators(t *testing.T) {
+	tenantID := func(value any, param string) error {
+		if !strings.HasPrefix(value.(string), param+"-") {
+			return fmt.Errorf("tenant %q must start with %s-", value, param)
+		}
+		return nil
+	}
+	even := func(value any, _ string) error {
+		if value.(int)%2 != 0 {
+			return fmt.Errorf("%d is odd", value)
+		}
+		return nil
+	}
+	validators := map[string]ValidatorFunc{"tenantID": tenantID, "even": even}
+
+	type tenantConfig struct {
+		Tenant  string `configly:"TENANT,required,tenantID=acme"`
+		Workers int    `configly:"WORKERS,min=2,even"`
+	}
+
+	t.Run("valid values", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{"TENANT": "acme-eu", "WORKERS": "4"})
+		l, err := New[tenantConfig](LoaderConfig{Sources: []sources.Source{source}, Validators: validators})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		cfg, err := l.Load()
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		if cfg.Tenant != "acme-eu" || cfg.Workers != 4 {
+			t.Errorf("expected acme-eu and 4, got: %s and %d", cfg.Tenant, cfg.Workers)
+		}
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{"TENANT": "other-eu", "WORKERS": "3"})
+		l, _ := New[tenantConfig](LoaderConfig{Sources: []sources.Source{source}, Validators: validators})
+		_, err := l.Load()
+		if err == nil {
+			t.Fatal("expected validation errors")
+		}
+		for _, expected := range []string{`tenantID: tenant "other-eu" must start with acme-`, "even: 3 is odd"} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %s", expected, err)
+			}
+		}
+	})
+
+	t.Run("built-in constraints run first", func(t *testing.T) {
+		source := sources.FromMap(map[string]string{"TENANT": "acme-eu", "WORKERS": "1"})
+		l, _ := New[tenantConfig](LoaderConfig{Sources: []sources.Source{source}, Validators: validators})
+		_, err := l.Load()
+		if err == nil || !strings.Contains(err.Error(), "less than minimum 2") || strings.Contains(err.Error(), "is odd") {
+			t.Errorf("expected only the minimum error, got: %v", err)
+		}
+	})
+
+	t.Run("with port", func(t *testing.T) {
+		evenPort := func(value any, _ string) error {
+			if fmt.Sprint(value)[len(fmt.Sprint(value))-1]%2 != 0 {
+				return fmt.Errorf("port %v is odd", value)
+			}
+			return nil
+		}
+		type portConfig struct {
+			Port      int    `configly:"PORT,port,even"`
+			AdminPort uint16 `configly:"ADMIN_PORT,port,evenPort"`
+		}
+		source := sources.FromMap(map[string]string{"PORT": "8081", "ADMIN_PORT": "9091"})
+		l, err := New[portConfig](LoaderConfig{
+			Sources:    []sources.Source{source},
+			Validators: map[string]ValidatorFunc{"even": even, "evenPort": evenPort},
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got: %s", err)
+		}
+		_, err = l.Load()
+		if err == nil {
+			t.Fatal("expected validation errors")
+		}
+		for _, expected := range []string{"even: 8081 is odd", "evenPort: port 9091 is odd"} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %s", expected, err)
+			}
+		}
+	})
+
+	t.Run("unknown option", func(t *testing.T) {
+		type typoConfig struct {
+			Name string `configly:"NAME,patern=foo"`
+			Port int    `configly:"PORT,requird"`
+		}
+		_, err := New[typoConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}})
+		if err == nil {
+			t.Fatal("expected parse errors")
+		}
+		for _, expected := range []string{`unknown tag option "patern=foo"`, `unknown tag option "requird"`} {
+			if !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %s", expected, err)
+			}
+		}
+	})
+
+	t.Run("invalid registrations", func(t *testing.T) {
+		tests := map[string]map[string]ValidatorFunc{
+			"conflicts with built-in tag option": {"required": even},
+			`invalid validator name "a=b"`:       {"a=b": even},
+			`invalid validator name ""`:          {"": even},
+			"function is nil":                    {"odd": nil},
+		}
+		for expected, validators := range tests {
+			_, err := New[tenantConfig](LoaderConfig{Sources: []sources.Source{sources.FromMap(nil)}, Validators: validators})
+			if err == nil || !strings.Contains(err.Error(), expected) {
+				t.Errorf("expected error containing %q, got: %v", expected, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package configly
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/zanedma/configly/sources"
+)
+
+// Watch watches every source that implements sources.Watcher and reloads the
+// configuration whenever one of them reports a change, passing the result of
+// each reload to onReload. Reloads are serialized, so onReload is never called
+// concurrently. Watch blocks until ctx is cancelled, in which case it returns
+// ctx.Err(), or until a watcher fails, in which case the remaining watchers
+// are stopped and the watcher's error is returned.
+// Returns an error immediately if no source supports watching.
+func (l *Loader[T]) Watch(ctx context.Context, onReload func(cfg *T, err error)) error {
+	var watchers []sources.Watcher
+	for _, source := range l.sources {
+		if watcher, ok := source.(sources.Watcher); ok {
+			watchers = append(watchers, watcher)
+		}
+	}
+	if len(watchers) == 0 {
+		return errors.New("no sources support watching")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var reloadMu sync.Mutex
+	reload := func() {
+		reloadMu.Lock()
+		defer reloadMu.Unlock()
+		l.logger.Debug().Msg("source changed, reloading")
+		onReload(l.Load())
+	}
+
+	errs := make(chan error, len(watchers))
+	for _, watcher := range watchers {
+		go func() {
+			errs <- watcher.Watch(ctx, reload)
+		}()
+	}
+
+	var watchErr error
+	for range watchers {
+		err := <-errs
+		if watchErr == nil && err != nil && ctx.Err() == nil {
+			watchErr = err
+			cancel()
+		}
+	}
+	if watchErr != nil {
+		return watchErr
+	}
+	return ctx.Err()
+}
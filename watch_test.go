@@ -0,0 +1,126 @@
+package configly
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zanedma/configly/sources"
+)
+
+// watchableSource is a MockSource whose changes are triggered by the test.
+type watchableSource struct {
+	sources.MockSource
+	changes chan map[string]string
+	err     error
+}
+
+func (s *watchableSource) Watch(ctx context.Context, onChange func()) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case values, ok := <-s.changes:
+			if !ok {
+				return s.err
+			}
+			s.Values = values
+			onChange()
+		}
+	}
+}
+
+func TestWatch(t *testing.T) {
+	t.Run("reload on change", func(t *testing.T) {
+		source := &watchableSource{
+			MockSource: sources.MockSource{SourceName: "watch", Values: map[string]string{"value": "v1"}},
+			changes:    make(chan map[string]string),
+		}
+		l, _ := New[validConfig](LoaderConfig{Sources: []sources.Source{source}})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		reloaded := make(chan *validConfig, 1)
+		done := make(chan error, 1)
+		go func() {
+			done <- l.Watch(ctx, func(cfg *validConfig, err error) {
+				if err != nil {
+					t.Errorf("expected err to be nil, got: %s", err)
+				}
+				reloaded <- cfg
+			})
+		}()
+
+		source.changes <- map[string]string{"value": "v2"}
+		select {
+		case cfg := <-reloaded:
+			if cfg.Value != "v2" {
+				t.Errorf("expected reloaded Value to be 'v2', got: %s", cfg.Value)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected onReload to be called")
+		}
+
+		cancel()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled after cancel, got: %v", err)
+		}
+	})
+
+	t.Run("report reload errors", func(t *testing.T) {
+		source := &watchableSource{
+			MockSource: sources.MockSource{SourceName: "watch", Values: map[string]string{"value": "v1"}},
+			changes:    make(chan map[string]string),
+		}
+		l, _ := New[validConfig](LoaderConfig{Sources: []sources.Source{source}})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		reloadErrs := make(chan error, 1)
+		go func() {
+			_ = l.Watch(ctx, func(cfg *validConfig, err error) { reloadErrs <- err })
+		}()
+
+		source.changes <- map[string]string{}
+		select {
+		case err := <-reloadErrs:
+			if err == nil {
+				t.Error("expected reload error for missing required value")
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("expected onReload to be called")
+		}
+	})
+
+	t.Run("return watcher error", func(t *testing.T) {
+		source := &watchableSource{
+			MockSource: sources.MockSource{SourceName: "watch"},
+			changes:    make(chan map[string]string),
+			err:        errors.New("watch failed"),
+		}
+		l, _ := New[validConfig](LoaderConfig{Sources: []sources.Source{source}})
+
+		close(source.changes)
+		err := l.Watch(context.Background(), func(*validConfig, error) {})
+		if err == nil || err.Error() != "watch failed" {
+			t.Errorf("expected watcher error, got: %v", err)
+		}
+	})
+
+	t.Run("error when no source supports watching", func(t *testing.T) {
+		l, _ := New[validConfig](LoaderConfig{Sources: []sources.Source{&sources.MockSource{SourceName: "test"}}})
+
+		if err := l.Watch(context.Background(), func(*validConfig, error) {}); err == nil {
+			t.Error("expected error when no source supports watching")
+		}
+	})
+	t.Run("error when wrapped sources do not support watching", func(t *testing.T) {
+		src := sources.Cached(sources.Prefixed(sources.FromMap(map[string]string{"APP_PORT": "8080"}), "APP_"), time.Minute)
+		l, _ := New[validConfig](LoaderConfig{Sources: []sources.Source{src}})
+
+		err := l.Watch(context.Background(), func(*validConfig, error) {})
+		if err == nil || err.Error() != "no sources support watching" {
+			t.Errorf("expected no watching sources error, got: %v", err)
+		}
+	})
+}